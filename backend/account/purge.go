@@ -0,0 +1,243 @@
+// Package account permanently removes accounts whose deletion grace
+// period has ended.
+package account
+
+import (
+	"errors"
+	"log"
+	"os"
+	"time"
+
+	"health-tracker/audit"
+	"health-tracker/config"
+	"health-tracker/database"
+	"health-tracker/models"
+	"health-tracker/utils"
+
+	"gorm.io/gorm"
+)
+
+// purgeInterval is how often scheduled deletions are checked
+const purgeInterval = time.Hour
+
+// Forum deletion policies
+const (
+	ForumPolicyAnonymize = "anonymize"
+	ForumPolicyDelete    = "delete"
+)
+
+// deletedUserEmail identifies the placeholder account that anonymized
+// forum posts and comments are reassigned to
+const deletedUserEmail = "deleted-user@health-tracker.invalid"
+
+// userOwnedModels are removed by user_id when an account is purged.
+// Every new model with a UserID column must be added here.
+var userOwnedModels = []interface{}{
+	&models.HealthData{},
+	&models.Symptom{},
+	&models.SymptomEpisode{},
+	&models.VitalSign{},
+	&models.SleepSession{},
+	&models.Workout{},
+	&models.MealLog{},
+	&models.WaterIntake{},
+	&models.Goal{},
+	&models.Reminder{},
+	&models.UnitPreference{},
+	&models.HealthAlert{},
+	&models.PasswordResetToken{},
+	&models.EmailVerificationToken{},
+	&models.RecoveryCode{},
+	&models.LoginAttempt{},
+	&models.UserIdentity{},
+	&models.APIKey{},
+	&models.DataExport{},
+	&models.Session{},
+}
+
+// StartPurgeWorker periodically purges accounts past their grace period
+func StartPurgeWorker() {
+	go func() {
+		for {
+			PurgeDue()
+			time.Sleep(purgeInterval)
+		}
+	}()
+}
+
+// PurgeDue purges every account whose scheduled deletion time has passed
+func PurgeDue() {
+	var users []models.User
+	database.DB.Where("deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= ?", time.Now()).Find(&users)
+
+	for _, user := range users {
+		if err := Purge(database.DB, user.ID, config.AppConfig.ForumDeletionPolicy); err != nil {
+			log.Printf("Failed to purge account %d: %v", user.ID, err)
+			continue
+		}
+		log.Printf("🗑️  Purged account %d", user.ID)
+		audit.Append(database.DB, audit.Entry{
+			Action:       models.AuditAccountPurged,
+			TargetUserID: user.ID,
+			Details:      audit.Details{"forum_policy": config.AppConfig.ForumDeletionPolicy},
+		})
+	}
+}
+
+// Purge permanently deletes a user and everything they own. Forum content
+// is reassigned to a placeholder account or removed depending on policy.
+func Purge(db *gorm.DB, userID uint, forumPolicy string) error {
+	var exportFiles []string
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.First(&user, userID).Error; err != nil {
+			return err
+		}
+
+		if forumPolicy == ForumPolicyDelete {
+			if err := deleteForumContent(tx, userID); err != nil {
+				return err
+			}
+		} else {
+			if err := anonymizeForumContent(tx, userID); err != nil {
+				return err
+			}
+		}
+
+		// Family links in both directions
+		if err := tx.Where("owner_id = ? OR member_user_id = ?", userID, userID).Delete(&models.FamilyMember{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.DataExport{}).Where("user_id = ? AND file_path <> ''", userID).
+			Pluck("file_path", &exportFiles).Error; err != nil {
+			return err
+		}
+
+		// Refresh tokens hang off sessions, not users
+		if err := tx.Where("session_id IN (?)", tx.Model(&models.Session{}).Select("id").Where("user_id = ?", userID)).
+			Delete(&models.RefreshToken{}).Error; err != nil {
+			return err
+		}
+
+		for _, model := range userOwnedModels {
+			if err := tx.Where("user_id = ?", userID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+
+		// Failed attempts against the address before it was linked to the user
+		if err := tx.Where("email = ?", user.Email).Delete(&models.LoginAttempt{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Delete(&user).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, path := range exportFiles {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			log.Printf("Failed to delete export file %s: %v", path, err)
+		}
+	}
+	return nil
+}
+
+// anonymizeForumContent keeps the user's posts and comments for the
+// discussion's sake but credits them to the placeholder account.
+// Likes are personal data and are removed.
+func anonymizeForumContent(tx *gorm.DB, userID uint) error {
+	placeholder, err := deletedUser(tx)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Model(&models.Post{}).Where("user_id = ?", userID).Update("user_id", placeholder.ID).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&models.Comment{}).Where("user_id = ?", userID).Update("user_id", placeholder.ID).Error; err != nil {
+		return err
+	}
+	return deleteLikes(tx, userID)
+}
+
+// deleteForumContent removes the user's posts (with all their comments and
+// likes), their comments on other posts and their likes
+func deleteForumContent(tx *gorm.DB, userID uint) error {
+	ownPosts := func() *gorm.DB {
+		return tx.Model(&models.Post{}).Select("id").Where("user_id = ?", userID)
+	}
+	if err := tx.Where("post_id IN (?)", ownPosts()).Delete(&models.Comment{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("post_id IN (?)", ownPosts()).Delete(&models.Like{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("user_id = ?", userID).Delete(&models.Post{}).Error; err != nil {
+		return err
+	}
+
+	// Keep comment counters on other people's posts in sync
+	var comments []models.Comment
+	if err := tx.Where("user_id = ?", userID).Find(&comments).Error; err != nil {
+		return err
+	}
+	for _, comment := range comments {
+		if err := tx.Model(&models.Post{}).Where("id = ?", comment.PostID).
+			Update("comments_count", gorm.Expr("comments_count - 1")).Error; err != nil {
+			return err
+		}
+	}
+	if err := tx.Where("user_id = ?", userID).Delete(&models.Comment{}).Error; err != nil {
+		return err
+	}
+
+	return deleteLikes(tx, userID)
+}
+
+func deleteLikes(tx *gorm.DB, userID uint) error {
+	var likes []models.Like
+	if err := tx.Where("user_id = ?", userID).Find(&likes).Error; err != nil {
+		return err
+	}
+	for _, like := range likes {
+		if err := tx.Model(&models.Post{}).Where("id = ?", like.PostID).
+			Update("likes_count", gorm.Expr("likes_count - 1")).Error; err != nil {
+			return err
+		}
+	}
+	return tx.Where("user_id = ?", userID).Delete(&models.Like{}).Error
+}
+
+// deletedUser returns the placeholder account, creating it on first use.
+// Its password is random and never revealed, so nobody can sign in as it.
+func deletedUser(tx *gorm.DB) (models.User, error) {
+	var user models.User
+	err := tx.Where("email = ?", deletedUserEmail).First(&user).Error
+	if err == nil {
+		return user, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return user, err
+	}
+
+	password, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return user, err
+	}
+	hashed, err := utils.HashPassword(password)
+	if err != nil {
+		return user, err
+	}
+
+	user = models.User{
+		Email:    deletedUserEmail,
+		Password: hashed,
+		Name:     "Pengguna dihapus",
+		Role:     models.RoleUser,
+	}
+	return user, tx.Create(&user).Error
+}
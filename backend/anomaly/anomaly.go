@@ -0,0 +1,198 @@
+// Package anomaly flags weight, symptom and hydration readings that are
+// far outside each user's own baseline, using median/MAD z-scores over a
+// rolling window.
+package anomaly
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"health-tracker/config"
+	"health-tracker/database"
+	"health-tracker/mailer"
+	"health-tracker/models"
+	"health-tracker/units"
+
+	"gorm.io/gorm/clause"
+)
+
+// detectInterval is how often every user's recent data is checked
+const detectInterval = time.Hour
+
+// recentDays limits alerts to the last week so the first run over old
+// history does not flood the user
+const recentDays = 7
+
+// StartWorker periodically checks all users for anomalies
+func StartWorker() {
+	go func() {
+		for {
+			DetectAll()
+			time.Sleep(detectInterval)
+		}
+	}()
+}
+
+// DetectAll runs Detect for every account not scheduled for deletion
+func DetectAll() {
+	var userIDs []uint
+	database.DB.Model(&models.User{}).Where("deletion_scheduled_at IS NULL").Pluck("id", &userIDs)
+
+	now := time.Now()
+	for _, userID := range userIDs {
+		if _, err := Detect(userID, now); err != nil {
+			log.Printf("Anomaly detection failed for user %d: %v", userID, err)
+		}
+	}
+}
+
+// Detect checks the user's recent weight, symptom and water data and
+// stores new alerts. Alerts already stored for the same metric and day,
+// including acknowledged ones, are not raised again. It returns the new
+// alerts.
+func Detect(userID uint, now time.Time) ([]models.HealthAlert, error) {
+	threshold := config.AppConfig.AnomalyZThreshold
+	today := now.Format("2006-01-02")
+	since := now.AddDate(0, 0, -recentDays).Format("2006-01-02")
+
+	weights, err := loadWeights(userID, now.AddDate(0, 0, -(recentDays+weightDetector.BaselineDays+14)))
+	if err != nil {
+		return nil, err
+	}
+	symptoms, err := loadSymptoms(userID, now.AddDate(0, 0, -(recentDays+symptomDetector.BaselineDays)))
+	if err != nil {
+		return nil, err
+	}
+	water, err := loadWater(userID, now.AddDate(0, 0, -(recentDays+waterDetector.BaselineDays)), today)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []models.HealthAlert
+	candidates = append(candidates, weightDetector.scan(weeklyChanges(weights), since, threshold)...)
+	candidates = append(candidates, symptomDetector.scan(dailyMax(symptoms, today), since, threshold)...)
+	candidates = append(candidates, waterDetector.scan(water, since, threshold)...)
+
+	// The worker and an on-demand check can run at the same time, so the
+	// unique index decides which of them raises the alert
+	var created []models.HealthAlert
+	for _, alert := range candidates {
+		alert.UserID = userID
+		result := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&alert)
+		if result.Error != nil {
+			return created, result.Error
+		}
+		if result.RowsAffected == 0 {
+			continue // already raised
+		}
+		created = append(created, alert)
+	}
+
+	if len(created) > 0 && config.AppConfig.AnomalyNotifyFamily {
+		notifyFamily(userID, created)
+	}
+	return created, nil
+}
+
+// loadWeights returns the last weigh-in of each day since the given time
+func loadWeights(userID uint, since time.Time) ([]observation, error) {
+	var records []models.HealthData
+	if err := database.DB.Where("user_id = ? AND weight_kg > 0 AND record_date >= ?", userID, since).
+		Order("record_date asc, id asc").Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	var weights []observation
+	for _, r := range records {
+		day := r.RecordDate.Local().Format("2006-01-02")
+		if n := len(weights); n > 0 && weights[n-1].Day == day {
+			weights[n-1].Value = r.WeightKg
+			continue
+		}
+		weights = append(weights, observation{Day: day, Value: r.WeightKg})
+	}
+	return weights, nil
+}
+
+// loadSymptoms returns the severity of every symptom logged since the
+// given time
+func loadSymptoms(userID uint, since time.Time) ([]observation, error) {
+	var records []models.Symptom
+	if err := database.DB.Where("user_id = ? AND logged_at >= ?", userID, since).
+		Order("logged_at asc").Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	obs := make([]observation, len(records))
+	for i, r := range records {
+		obs[i] = observation{Day: r.LoggedAt.Local().Format("2006-01-02"), Value: float64(r.Severity)}
+	}
+	return obs, nil
+}
+
+// loadWater returns the daily intake since the given time. Today is left
+// out because the day is not over yet.
+func loadWater(userID uint, since time.Time, today string) ([]observation, error) {
+	var records []models.WaterIntake
+	if err := database.DB.Where("user_id = ? AND date >= ? AND date < ?", userID, since.Format("2006-01-02"), today).
+		Order("date asc").Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	obs := make([]observation, len(records))
+	for i, r := range records {
+		obs[i] = observation{Day: r.Date, Value: float64(r.AmountMl)}
+	}
+	return obs, nil
+}
+
+// notifyFamily emails the family members allowed to view the user's
+// health about new alerts, if the user agreed to share them
+func notifyFamily(userID uint, alerts []models.HealthAlert) {
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil || !user.ShareAlertsWithFamily {
+		return
+	}
+
+	var links []models.FamilyMember
+	database.DB.Where("member_user_id = ? AND status = ? AND can_view_health = ?", userID, "approved", true).Find(&links)
+
+	notified := false
+	for _, link := range links {
+		var owner models.User
+		if err := database.DB.First(&owner, link.OwnerID).Error; err != nil {
+			continue
+		}
+
+		prefs := units.Metric
+		var pref models.UnitPreference
+		if database.DB.Where("user_id = ?", owner.ID).First(&pref).Error == nil {
+			prefs = pref.Units()
+		}
+
+		body := fmt.Sprintf("Halo %s,\n\nAda perubahan kesehatan yang tidak biasa pada %s:\n\n", owner.Name, user.Name)
+		for _, alert := range alerts {
+			body += fmt.Sprintf("- %s: %s\n", alert.ObservedOn, models.NewHealthAlertView(alert, prefs).Message)
+		}
+		body += "\nCek kondisinya dan lihat detail di menu Keluarga Health Tracker."
+
+		if err := mailer.AppMailer.Send(mailer.Message{
+			To:      owner.Email,
+			Subject: "Peringatan Kesehatan Keluarga",
+			Body:    body,
+		}); err != nil {
+			log.Printf("Failed to send health alert email to user %d: %v", owner.ID, err)
+			continue
+		}
+		notified = true
+	}
+
+	if notified {
+		ids := make([]uint, len(alerts))
+		for i, alert := range alerts {
+			ids[i] = alert.ID
+		}
+		database.DB.Model(&models.HealthAlert{}).Where("id IN ?", ids).Update("notified_at", time.Now())
+	}
+}
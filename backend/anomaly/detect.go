@@ -0,0 +1,176 @@
+package anomaly
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"health-tracker/models"
+)
+
+// madScale turns the median absolute deviation into a standard deviation
+// estimate for normal data (Iglewicz and Hoaglin's modified z-score)
+const madScale = 0.6745
+
+// minBaseline is the fewest earlier observations an observation is
+// compared against
+const minBaseline = 5
+
+// observation is one value of a metric on a day (YYYY-MM-DD)
+type observation struct {
+	Day   string
+	Value float64
+}
+
+// detector scores each observation against the ones in the baselineDays
+// before it
+type detector struct {
+	Metric       string
+	BaselineDays int
+	// MinScale floors the MAD so a very regular baseline does not turn
+	// tiny changes into huge z-scores
+	MinScale float64
+	// Direction limits alerts to increases (+1) or decreases (-1); 0
+	// alerts on both
+	Direction int
+}
+
+var (
+	weightDetector  = detector{Metric: models.AlertWeightChange, BaselineDays: 90, MinScale: 0.25}
+	symptomDetector = detector{Metric: models.AlertSymptomSeverity, BaselineDays: 28, MinScale: 1, Direction: 1}
+	waterDetector   = detector{Metric: models.AlertWaterIntake, BaselineDays: 28, MinScale: 100, Direction: -1}
+)
+
+// scan returns an alert for every observation on or after since whose
+// robust z-score reaches threshold. A run of anomalous observations in the
+// same direction is one episode and only its first day raises an alert.
+// Observations must be sorted by day.
+func (d detector) scan(obs []observation, since string, threshold float64) []models.HealthAlert {
+	var alerts []models.HealthAlert
+	previous := 0 // direction of the previous observation if it was anomalous
+	for i, o := range obs {
+		day, _ := time.Parse("2006-01-02", o.Day)
+		start := day.AddDate(0, 0, -d.BaselineDays).Format("2006-01-02")
+		var baseline []float64
+		for _, b := range obs[:i] {
+			if b.Day >= start {
+				baseline = append(baseline, b.Value)
+			}
+		}
+		if len(baseline) < minBaseline {
+			previous = 0
+			continue
+		}
+
+		med := median(baseline)
+		z := robustZ(o.Value, med, mad(baseline, med), d.MinScale)
+		if math.Abs(z) < threshold || (d.Direction > 0 && z < 0) || (d.Direction < 0 && z > 0) {
+			previous = 0
+			continue
+		}
+
+		direction := 1
+		if z < 0 {
+			direction = -1
+		}
+		ongoing := direction == previous
+		previous = direction
+		if ongoing || o.Day < since {
+			continue
+		}
+
+		alert := models.HealthAlert{
+			Metric:       d.Metric,
+			ObservedOn:   o.Day,
+			Direction:    "increase",
+			Severity:     models.AlertWarning,
+			Value:        o.Value,
+			Baseline:     med,
+			ZScore:       z,
+			BaselineSize: len(baseline),
+		}
+		if direction < 0 {
+			alert.Direction = "decrease"
+		}
+		if math.Abs(z) >= 2*threshold {
+			alert.Severity = models.AlertCritical
+		}
+		alerts = append(alerts, alert)
+	}
+	return alerts
+}
+
+// weeklyChanges turns daily weights into the change over the previous
+// week. The reference is the last weigh-in 7 to 14 days earlier and the
+// change is scaled to 7 days.
+func weeklyChanges(weights []observation) []observation {
+	var changes []observation
+	for i, w := range weights {
+		day, _ := time.Parse("2006-01-02", w.Day)
+		latest := day.AddDate(0, 0, -7).Format("2006-01-02")
+		earliest := day.AddDate(0, 0, -14).Format("2006-01-02")
+
+		for j := i - 1; j >= 0; j-- {
+			ref := weights[j]
+			if ref.Day > latest {
+				continue
+			}
+			if ref.Day < earliest {
+				break
+			}
+			refDay, _ := time.Parse("2006-01-02", ref.Day)
+			days := day.Sub(refDay).Hours() / 24
+			changes = append(changes, observation{Day: w.Day, Value: (w.Value - ref.Value) / days * 7})
+			break
+		}
+	}
+	return changes
+}
+
+// dailyMax keeps the highest value of each day and fills the days between
+// the first observation and until with zero
+func dailyMax(obs []observation, until string) []observation {
+	if len(obs) == 0 {
+		return nil
+	}
+	highest := map[string]float64{}
+	for _, o := range obs {
+		if o.Value > highest[o.Day] {
+			highest[o.Day] = o.Value
+		}
+	}
+
+	var days []observation
+	day, _ := time.Parse("2006-01-02", obs[0].Day)
+	for d := day.Format("2006-01-02"); d <= until; d = day.Format("2006-01-02") {
+		days = append(days, observation{Day: d, Value: highest[d]})
+		day = day.AddDate(0, 0, 1)
+	}
+	return days
+}
+
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+// mad is the median absolute deviation from med
+func mad(values []float64, med float64) float64 {
+	deviations := make([]float64, len(values))
+	for i, v := range values {
+		deviations[i] = math.Abs(v - med)
+	}
+	return median(deviations)
+}
+
+func robustZ(value, med, mad, minScale float64) float64 {
+	if mad < minScale {
+		mad = minScale
+	}
+	return madScale * (value - med) / mad
+}
@@ -0,0 +1,92 @@
+// Package audit writes the append-only security audit log.
+package audit
+
+import (
+	"encoding/json"
+	"log"
+
+	"health-tracker/database"
+	"health-tracker/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Details holds action-specific context stored as JSON
+type Details map[string]interface{}
+
+// Entry describes one audited action
+type Entry struct {
+	Action       string
+	ActorID      uint // defaults to the authenticated user
+	TargetUserID uint
+	ResourceType string
+	ResourceID   string
+	Details      Details
+}
+
+// Record appends an entry for the current request. Failures are logged
+// and never block the action being audited.
+func Record(c *gin.Context, e Entry) {
+	if e.ActorID == 0 {
+		e.ActorID = c.GetUint("userID")
+	}
+
+	entry := build(e)
+	entry.IPAddress = c.ClientIP()
+	entry.UserAgent = truncate(c.Request.UserAgent(), 255)
+	if e.ActorID == c.GetUint("userID") {
+		entry.ActorEmail = c.GetString("userEmail")
+	}
+
+	write(database.DB, entry)
+}
+
+// Append writes an entry outside a request, for background jobs
+func Append(db *gorm.DB, e Entry) {
+	write(db, build(e))
+}
+
+func build(e Entry) models.AuditLog {
+	entry := models.AuditLog{
+		Action:       e.Action,
+		ActorID:      optionalID(e.ActorID),
+		TargetUserID: optionalID(e.TargetUserID),
+		ResourceType: e.ResourceType,
+		ResourceID:   e.ResourceID,
+	}
+	if len(e.Details) > 0 {
+		if b, err := json.Marshal(e.Details); err == nil {
+			entry.Details = string(b)
+		}
+	}
+	return entry
+}
+
+func write(db *gorm.DB, entry models.AuditLog) {
+	// Background entries have no request context to take the email from
+	if entry.ActorEmail == "" && entry.ActorID != nil {
+		var actor models.User
+		if db.Select("email").First(&actor, *entry.ActorID).Error == nil {
+			entry.ActorEmail = actor.Email
+		}
+	}
+
+	if err := db.Create(&entry).Error; err != nil {
+		log.Printf("Failed to write audit log entry %s: %v", entry.Action, err)
+	}
+}
+
+func optionalID(id uint) *uint {
+	if id == 0 {
+		return nil
+	}
+	return &id
+}
+
+func truncate(s string, max int) string {
+	if len(s) > max {
+		return s[:max]
+	}
+	return s
+}
@@ -0,0 +1,34 @@
+// Command mockoidc runs a local OpenID Connect provider for development.
+// Every authorization request is approved for the configured user.
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+
+	"health-tracker/oidc/oidctest"
+)
+
+func main() {
+	addr := flag.String("addr", "127.0.0.1:9400", "listen address")
+	clientID := flag.String("client-id", "health-tracker", "expected client_id")
+	sub := flag.String("sub", "mock-user-1", "subject of the signed-in user")
+	email := flag.String("email", "mock.user@example.com", "email of the signed-in user")
+	verified := flag.Bool("email-verified", true, "whether the email is verified")
+	name := flag.String("name", "Mock User", "display name")
+	flag.Parse()
+
+	server, err := oidctest.NewServer("http://"+*addr, *clientID, oidctest.User{
+		Subject:       *sub,
+		Email:         *email,
+		EmailVerified: *verified,
+		Name:          *name,
+	})
+	if err != nil {
+		log.Fatalf("Failed to create mock provider: %v", err)
+	}
+
+	log.Printf("Mock OIDC provider listening on http://%s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, server.Handler()))
+}
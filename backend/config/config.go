@@ -1,8 +1,11 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -14,24 +17,263 @@ type Config struct {
 	JWTExpiryHours int
 	DatabasePath   string // Untuk SQLite (Local)
 	DatabaseURL    string // Untuk PostgreSQL (Render/Neon)
+
+	RefreshTokenExpiryDays int
+
+	// JWT signing keys
+	JWTAlgorithm       string // RS256, EdDSA, atau HS256 (pakai JWTSecret)
+	JWTKeyDir          string
+	JWTKeyRotationDays int
+
+	// Mailer
+	MailDriver   string // smtp, file, memory
+	MailFrom     string
+	MailDir      string // Folder output untuk driver file
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+
+	FrontendURL                string // Dipakai untuk link di email
+	BackendURL                 string // URL publik API, untuk link email yang langsung ke backend (unduh ekspor)
+	PasswordResetExpiryMinutes int
+
+	// Email verification
+	EmailVerificationExpiryHours int
+	RequireVerifiedEmail         bool // Blokir undangan keluarga & posting forum untuk email belum terverifikasi
+
+	// Two-factor authentication
+	TOTPIssuer                  string
+	TwoFactorTokenExpiryMinutes int
+
+	// Login lockout: setelah LoginLockoutThreshold gagal berturut-turut, akun dikunci
+	// LoginLockoutBaseMinutes, lalu dua kali lipat setiap kegagalan berikutnya
+	LoginLockoutThreshold   int
+	LoginLockoutBaseMinutes int
+	LoginLockoutMaxMinutes  int
+
+	// Social login (OpenID Connect)
+	OIDCProviders          []OIDCProvider
+	OIDCStateExpiryMinutes int
+
+	// Account export: ekspor kecil langsung diunduh, yang besar diproses di background
+	ExportDir             string
+	ExportSyncMaxRecords  int
+	ExportLinkExpiryHours int
+
+	// Penghapusan akun: dibatalkan jika user login lagi sebelum masa tenggang habis
+	AccountDeletionGraceDays int
+	ForumDeletionPolicy      string // anonymize (default) atau delete
+
+	// Target tidur per malam untuk hitung sleep debt, jika user belum punya goal tidur
+	SleepTargetHours float64
+
+	// Skema klasifikasi BMI dewasa: who (default) atau asia_pacific
+	BMIAdultScheme string
+
+	// Jumlah hari data berat badan yang dipakai untuk tren & prediksi goal
+	WeightForecastWindowDays int
+
+	// Deteksi anomali: ambang robust z-score (median/MAD) dan opsi email ke keluarga
+	AnomalyZThreshold   float64
+	AnomalyNotifyFamily bool
+
+	// Bobot komponen health score, mis. "bmi=20,symptoms=25,sleep=10"
+	HealthScoreWeights string
+}
+
+// OIDCProvider holds the client settings for one OpenID Connect provider
+type OIDCProvider struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
 }
 
 var AppConfig *Config
 
+// DefaultJWTSecret is the placeholder secret used when JWT_SECRET is not set
+const DefaultJWTSecret = "default-secret-key"
+
+// DefaultHealthScoreWeights is used when HEALTH_SCORE_WEIGHTS is not set
+const DefaultHealthScoreWeights = "bmi=20,symptoms=25,hydration=15,activity=15,mood=15,sleep=10"
+
+// healthScoreComponents are the component names HEALTH_SCORE_WEIGHTS
+// accepts
+var healthScoreComponents = []string{"bmi", "symptoms", "hydration", "activity", "mood", "sleep"}
+
 func LoadConfig() {
 	godotenv.Load()
 
 	expiryHours, _ := strconv.Atoi(getEnv("JWT_EXPIRY_HOURS", "24"))
+	refreshExpiryDays, _ := strconv.Atoi(getEnv("REFRESH_TOKEN_EXPIRY_DAYS", "30"))
+	keyRotationDays, _ := strconv.Atoi(getEnv("JWT_KEY_ROTATION_DAYS", "30"))
+	resetExpiryMinutes, _ := strconv.Atoi(getEnv("PASSWORD_RESET_EXPIRY_MINUTES", "30"))
+	verificationExpiryHours, _ := strconv.Atoi(getEnv("EMAIL_VERIFICATION_EXPIRY_HOURS", "48"))
+	requireVerifiedEmail, _ := strconv.ParseBool(getEnv("REQUIRE_VERIFIED_EMAIL", "false"))
+	twoFactorExpiryMinutes, _ := strconv.Atoi(getEnv("TWO_FACTOR_TOKEN_EXPIRY_MINUTES", "5"))
+	lockoutThreshold, _ := strconv.Atoi(getEnv("LOGIN_LOCKOUT_THRESHOLD", "5"))
+	lockoutBaseMinutes, _ := strconv.Atoi(getEnv("LOGIN_LOCKOUT_BASE_MINUTES", "1"))
+	lockoutMaxMinutes, _ := strconv.Atoi(getEnv("LOGIN_LOCKOUT_MAX_MINUTES", "60"))
+	oidcStateExpiryMinutes, _ := strconv.Atoi(getEnv("OIDC_STATE_EXPIRY_MINUTES", "10"))
+	exportSyncMaxRecords, _ := strconv.Atoi(getEnv("EXPORT_SYNC_MAX_RECORDS", "2000"))
+	exportLinkExpiryHours, _ := strconv.Atoi(getEnv("EXPORT_LINK_EXPIRY_HOURS", "24"))
+	deletionGraceDays, _ := strconv.Atoi(getEnv("ACCOUNT_DELETION_GRACE_DAYS", "14"))
+	sleepTargetHours, _ := strconv.ParseFloat(getEnv("SLEEP_TARGET_HOURS", "8"), 64)
+	forecastWindowDays, _ := strconv.Atoi(getEnv("WEIGHT_FORECAST_WINDOW_DAYS", "28"))
+	anomalyZThreshold, _ := strconv.ParseFloat(getEnv("ANOMALY_Z_THRESHOLD", "3.5"), 64)
+	anomalyNotifyFamily, _ := strconv.ParseBool(getEnv("ANOMALY_NOTIFY_FAMILY", "false"))
+
+	port := getEnv("PORT", "8080")
 
 	AppConfig = &Config{
-		Port:           getEnv("PORT", "8080"),
+		Port:           port,
 		GinMode:        getEnv("GIN_MODE", "debug"),
-		JWTSecret:      getEnv("JWT_SECRET", "default-secret-key"),
+		JWTSecret:      getEnv("JWT_SECRET", DefaultJWTSecret),
 		JWTExpiryHours: expiryHours,
 		DatabasePath:   getEnv("DATABASE_PATH", "./health_tracker.db"),
 		// INI YANG BARU: Membaca Environment Variable DB_URL dari Render
 		DatabaseURL: getEnv("DB_URL", ""),
+
+		RefreshTokenExpiryDays: refreshExpiryDays,
+
+		JWTAlgorithm:       getEnv("JWT_ALGORITHM", "RS256"),
+		JWTKeyDir:          getEnv("JWT_KEY_DIR", "./keys"),
+		JWTKeyRotationDays: keyRotationDays,
+
+		MailDriver:   getEnv("MAIL_DRIVER", "memory"),
+		MailFrom:     getEnv("MAIL_FROM", "Health Tracker <no-reply@health-tracker.local>"),
+		MailDir:      getEnv("MAIL_DIR", "./mail"),
+		SMTPHost:     getEnv("SMTP_HOST", ""),
+		SMTPPort:     getEnv("SMTP_PORT", "587"),
+		SMTPUsername: getEnv("SMTP_USERNAME", ""),
+		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+
+		FrontendURL:                getEnv("FRONTEND_URL", "http://localhost:5173"),
+		BackendURL:                 strings.TrimSuffix(getEnv("BACKEND_URL", "http://localhost:"+port), "/"),
+		PasswordResetExpiryMinutes: resetExpiryMinutes,
+
+		EmailVerificationExpiryHours: verificationExpiryHours,
+		RequireVerifiedEmail:         requireVerifiedEmail,
+
+		TOTPIssuer:                  getEnv("TOTP_ISSUER", "Health Tracker"),
+		TwoFactorTokenExpiryMinutes: twoFactorExpiryMinutes,
+
+		LoginLockoutThreshold:   lockoutThreshold,
+		LoginLockoutBaseMinutes: lockoutBaseMinutes,
+		LoginLockoutMaxMinutes:  lockoutMaxMinutes,
+
+		OIDCStateExpiryMinutes: oidcStateExpiryMinutes,
+
+		ExportDir:             getEnv("EXPORT_DIR", "./exports"),
+		ExportSyncMaxRecords:  exportSyncMaxRecords,
+		ExportLinkExpiryHours: exportLinkExpiryHours,
+
+		AccountDeletionGraceDays: deletionGraceDays,
+		ForumDeletionPolicy:      getEnv("FORUM_DELETION_POLICY", "anonymize"),
+
+		SleepTargetHours: sleepTargetHours,
+
+		BMIAdultScheme: getEnv("BMI_ADULT_SCHEME", "who"),
+
+		WeightForecastWindowDays: forecastWindowDays,
+
+		AnomalyZThreshold:   anomalyZThreshold,
+		AnomalyNotifyFamily: anomalyNotifyFamily,
+
+		HealthScoreWeights: getEnv("HEALTH_SCORE_WEIGHTS", DefaultHealthScoreWeights),
+	}
+	AppConfig.OIDCProviders = loadOIDCProviders(AppConfig.FrontendURL)
+}
+
+// loadOIDCProviders membaca OIDC_PROVIDERS (mis. "google,keycloak") lalu
+// OIDC_<NAMA>_ISSUER, _CLIENT_ID, _CLIENT_SECRET, _REDIRECT_URL, _SCOPES
+func loadOIDCProviders(frontendURL string) []OIDCProvider {
+	var providers []OIDCProvider
+	for _, name := range strings.Split(getEnv("OIDC_PROVIDERS", ""), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+
+		provider := OIDCProvider{
+			Name:         name,
+			Issuer:       getEnv(prefix+"ISSUER", ""),
+			ClientID:     getEnv(prefix+"CLIENT_ID", ""),
+			ClientSecret: getEnv(prefix+"CLIENT_SECRET", ""),
+			RedirectURL:  getEnv(prefix+"REDIRECT_URL", strings.TrimSuffix(frontendURL, "/")+"/auth/callback/"+name),
+			Scopes:       strings.Fields(strings.ReplaceAll(getEnv(prefix+"SCOPES", "openid email profile"), ",", " ")),
+		}
+		providers = append(providers, provider)
+	}
+	return providers
+}
+
+// Validate refuses configurations that are unsafe to run in production
+func (c *Config) Validate() error {
+	// The secret only signs tokens with HS256; RS256 and EdDSA use the key directory
+	if c.GinMode == "release" && c.JWTAlgorithm == "HS256" && c.JWTSecret == DefaultJWTSecret {
+		return errors.New("JWT_SECRET must be set in release mode when JWT_ALGORITHM is HS256")
+	}
+	if c.ForumDeletionPolicy != "anonymize" && c.ForumDeletionPolicy != "delete" {
+		return fmt.Errorf("FORUM_DELETION_POLICY must be anonymize or delete, got %q", c.ForumDeletionPolicy)
+	}
+	for _, p := range c.OIDCProviders {
+		if p.Issuer == "" || p.ClientID == "" {
+			return fmt.Errorf("OIDC provider %q needs an issuer and client ID", p.Name)
+		}
+	}
+	if c.SleepTargetHours < 4 || c.SleepTargetHours > 14 {
+		return fmt.Errorf("SLEEP_TARGET_HOURS must be between 4 and 14")
+	}
+	if c.BMIAdultScheme != "who" && c.BMIAdultScheme != "asia_pacific" {
+		return fmt.Errorf("BMI_ADULT_SCHEME must be who or asia_pacific, got %q", c.BMIAdultScheme)
+	}
+	if c.WeightForecastWindowDays < 7 || c.WeightForecastWindowDays > 180 {
+		return fmt.Errorf("WEIGHT_FORECAST_WINDOW_DAYS must be between 7 and 180")
+	}
+	if c.AnomalyZThreshold < 2 || c.AnomalyZThreshold > 10 {
+		return fmt.Errorf("ANOMALY_Z_THRESHOLD must be between 2 and 10")
+	}
+	if _, err := c.ScoreWeights(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ScoreWeights parses HEALTH_SCORE_WEIGHTS. Components left out get no
+// weight and are not scored.
+func (c *Config) ScoreWeights() (map[string]float64, error) {
+	weights := make(map[string]float64)
+	var total float64
+	for _, entry := range strings.Split(c.HealthScoreWeights, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, value, found := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		weight, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if !found || err != nil || weight < 0 {
+			return nil, fmt.Errorf("HEALTH_SCORE_WEIGHTS entry %q must be name=weight with a weight of 0 or more", entry)
+		}
+		known := false
+		for _, n := range healthScoreComponents {
+			known = known || n == name
+		}
+		if !known {
+			return nil, fmt.Errorf("HEALTH_SCORE_WEIGHTS has unknown component %q, use %s", name, strings.Join(healthScoreComponents, ", "))
+		}
+		weights[name] = weight
+		total += weight
+	}
+	if total == 0 {
+		return nil, errors.New("HEALTH_SCORE_WEIGHTS must give at least one component a weight")
 	}
+	return weights, nil
 }
 
 func getEnv(key, defaultValue string) string {
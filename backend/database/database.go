@@ -3,6 +3,7 @@ package database
 import (
 	"health-tracker/config"
 	"health-tracker/models"
+	"health-tracker/units"
 	"log"
 
 	"gorm.io/driver/postgres" // IMPORT BARU: Driver Postgres
@@ -38,6 +39,11 @@ func InitDatabase() {
 
 	log.Println("Database connected successfully")
 
+	// Accounts from before email verification existed are grandfathered
+	// in once the column is added, see grandfatherEmailVerification
+	legacyUsers := DB.Migrator().HasTable(&models.User{}) &&
+		!DB.Migrator().HasColumn(&models.User{}, "EmailVerified")
+
 	// Auto-migrate models
 	err = DB.AutoMigrate(
 		&models.User{},
@@ -53,6 +59,25 @@ func InitDatabase() {
 		&models.WaterIntake{},
 		&models.Goal{},
 		&models.Reminder{},
+		&models.PasswordResetToken{},
+		&models.Session{},
+		&models.RefreshToken{},
+		&models.EmailVerificationToken{},
+		&models.RecoveryCode{},
+		&models.LoginAttempt{},
+		&models.UserIdentity{},
+		&models.OIDCAuthRequest{},
+		&models.APIKey{},
+		&models.DataExport{},
+		&models.AuditLog{},
+		&models.VitalSign{},
+		&models.SleepSession{},
+		&models.Workout{},
+		&models.Food{},
+		&models.MealLog{},
+		&models.UnitPreference{},
+		&models.HealthAlert{},
+		&models.SymptomEpisode{},
 	)
 
 	if err != nil {
@@ -61,6 +86,33 @@ func InitDatabase() {
 
 	log.Println("Database migration completed")
 
+	if legacyUsers {
+		grandfatherEmailVerification()
+	}
+	backfillWaterVolumes()
+
 	// Seed initial data
 	SeedData()
 }
+
+// grandfatherEmailVerification marks every existing account as verified.
+// It runs once, in the migration that adds email_verified, so turning on
+// REQUIRE_VERIFIED_EMAIL or linking a social login does not lock out
+// users who signed up before verification emails were sent.
+func grandfatherEmailVerification() {
+	result := DB.Model(&models.User{}).Where("email_verified = ?", false).Update("email_verified", true)
+	if result.Error != nil {
+		log.Println("Failed to mark existing users as verified:", result.Error)
+		return
+	}
+	log.Printf("Marked %d existing users as verified", result.RowsAffected)
+}
+
+// backfillWaterVolumes fills amount_ml and goal_ml on water records
+// written before intake was stored in millilitres
+func backfillWaterVolumes() {
+	DB.Model(&models.WaterIntake{}).Where("amount_ml = 0 AND glasses > 0").
+		Update("amount_ml", gorm.Expr("glasses * ?", units.MlPerGlass))
+	DB.Model(&models.WaterIntake{}).Where("goal_ml = ? AND goal <> ?", 2000, 8).
+		Update("goal_ml", gorm.Expr("goal * ?", units.MlPerGlass))
+}
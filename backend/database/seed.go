@@ -46,6 +46,15 @@ func SeedData() {
 		}
 	}
 
+	// Seed nutrition database
+	var foodCount int64
+	DB.Model(&models.Food{}).Count(&foodCount)
+	if foodCount == 0 {
+		log.Println("Seeding foods...")
+		foods := models.GetSeedFoods()
+		DB.Create(&foods)
+	}
+
 	log.Println("Seed data completed")
 }
 
@@ -0,0 +1,171 @@
+// Package export builds the downloadable archive of everything a user has
+// stored, as JSON and CSV files with a manifest.
+package export
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"reflect"
+	"time"
+
+	"health-tracker/models"
+
+	"gorm.io/gorm"
+)
+
+// SchemaVersion is bumped whenever a file is added, removed or changes shape:
+//
+//	2: vital_signs added
+//	3: sleep_sessions added
+//	4: workouts added
+//	5: meals added
+//	6: unit_preferences added, amount_ml and goal_ml on water_intake
+//	7: health_alerts added
+//	8: symptom_episodes added, episode_id on symptoms
+//	9: triggers_text on symptom_episodes
+const SchemaVersion = 9
+
+// Section is one exported dataset, written as <Name>.json and <Name>.csv
+type Section struct {
+	Name  string
+	Model interface{} // pointer to the model struct
+	Scope func(db *gorm.DB, userID uint) *gorm.DB
+}
+
+func byUserID(db *gorm.DB, userID uint) *gorm.DB {
+	return db.Where("user_id = ?", userID)
+}
+
+// Sections lists every user-owned model included in the export
+var Sections = []Section{
+	{Name: "profile", Model: &models.User{}, Scope: func(db *gorm.DB, userID uint) *gorm.DB {
+		return db.Where("id = ?", userID)
+	}},
+	{Name: "health_data", Model: &models.HealthData{}, Scope: byUserID},
+	{Name: "symptoms", Model: &models.Symptom{}, Scope: byUserID},
+	{Name: "symptom_episodes", Model: &models.SymptomEpisode{}, Scope: byUserID},
+	{Name: "vital_signs", Model: &models.VitalSign{}, Scope: byUserID},
+	{Name: "sleep_sessions", Model: &models.SleepSession{}, Scope: byUserID},
+	{Name: "workouts", Model: &models.Workout{}, Scope: byUserID},
+	{Name: "meals", Model: &models.MealLog{}, Scope: byUserID},
+	{Name: "water_intake", Model: &models.WaterIntake{}, Scope: byUserID},
+	{Name: "goals", Model: &models.Goal{}, Scope: byUserID},
+	{Name: "reminders", Model: &models.Reminder{}, Scope: byUserID},
+	{Name: "unit_preferences", Model: &models.UnitPreference{}, Scope: byUserID},
+	{Name: "health_alerts", Model: &models.HealthAlert{}, Scope: byUserID},
+	// Family links in both directions: people the user invited and invitations they received
+	{Name: "family_members", Model: &models.FamilyMember{}, Scope: func(db *gorm.DB, userID uint) *gorm.DB {
+		return db.Where("owner_id = ? OR member_user_id = ?", userID, userID)
+	}},
+	{Name: "forum_posts", Model: &models.Post{}, Scope: byUserID},
+	{Name: "forum_comments", Model: &models.Comment{}, Scope: byUserID},
+	{Name: "forum_likes", Model: &models.Like{}, Scope: byUserID},
+}
+
+// Manifest describes the archive contents
+type Manifest struct {
+	Format        string         `json:"format"`
+	SchemaVersion int            `json:"schema_version"`
+	GeneratedAt   time.Time      `json:"generated_at"`
+	UserID        uint           `json:"user_id"`
+	TotalRecords  int64          `json:"total_records"`
+	Files         []ManifestFile `json:"files"`
+}
+
+type ManifestFile struct {
+	Name    string   `json:"name"`
+	Records int      `json:"records"`
+	JSON    string   `json:"json"`
+	CSV     string   `json:"csv"`
+	Columns []string `json:"columns"`
+}
+
+// CountRecords returns how many rows the user's export will contain
+func CountRecords(db *gorm.DB, userID uint) (int64, error) {
+	var total int64
+	for _, s := range Sections {
+		var n int64
+		if err := s.Scope(db.Model(s.Model), userID).Count(&n).Error; err != nil {
+			return 0, err
+		}
+		total += n
+	}
+	return total, nil
+}
+
+// WriteArchive writes the user's ZIP export to w
+func WriteArchive(w io.Writer, db *gorm.DB, userID uint) (*Manifest, error) {
+	zw := zip.NewWriter(w)
+
+	manifest := &Manifest{
+		Format:        "health-tracker-export",
+		SchemaVersion: SchemaVersion,
+		GeneratedAt:   time.Now().UTC(),
+		UserID:        userID,
+	}
+
+	for _, s := range Sections {
+		rows := reflect.New(reflect.SliceOf(reflect.TypeOf(s.Model).Elem()))
+		if err := s.Scope(db.Model(s.Model), userID).Order("id").Find(rows.Interface()).Error; err != nil {
+			return nil, err
+		}
+		t := newTable(rows.Elem().Interface())
+
+		file := ManifestFile{
+			Name:    s.Name,
+			Records: len(t.Rows),
+			JSON:    s.Name + ".json",
+			CSV:     s.Name + ".csv",
+			Columns: t.Columns,
+		}
+		if err := writeJSON(zw, file.JSON, manifest.GeneratedAt, t); err != nil {
+			return nil, err
+		}
+		if err := writeCSV(zw, file.CSV, manifest.GeneratedAt, t); err != nil {
+			return nil, err
+		}
+
+		manifest.Files = append(manifest.Files, file)
+		manifest.TotalRecords += int64(file.Records)
+	}
+
+	if err := writeJSON(zw, "manifest.json", manifest.GeneratedAt, manifest); err != nil {
+		return nil, err
+	}
+
+	return manifest, zw.Close()
+}
+
+func createFile(zw *zip.Writer, name string, modified time.Time) (io.Writer, error) {
+	return zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+}
+
+func writeJSON(zw *zip.Writer, name string, modified time.Time, v interface{}) error {
+	f, err := createFile(zw, name, modified)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func writeCSV(zw *zip.Writer, name string, modified time.Time, t table) error {
+	f, err := createFile(zw, name, modified)
+	if err != nil {
+		return err
+	}
+	cw := csv.NewWriter(f)
+	if err := cw.Write(t.Columns); err != nil {
+		return err
+	}
+	for _, row := range t.Rows {
+		if err := cw.Write(csvRecord(row)); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
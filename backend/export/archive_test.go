@@ -0,0 +1,107 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"health-tracker/models"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	for _, s := range Sections {
+		if err := db.AutoMigrate(s.Model); err != nil {
+			t.Fatalf("migrate %s: %v", s.Name, err)
+		}
+	}
+	return db
+}
+
+func readZipFile(t *testing.T, zr *zip.Reader, name string) []byte {
+	t.Helper()
+	f, err := zr.Open(name)
+	if err != nil {
+		t.Fatalf("open %s: %v", name, err)
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatalf("read %s: %v", name, err)
+	}
+	return data
+}
+
+func TestWriteArchiveIncludesEpisodeTriggers(t *testing.T) {
+	db := openTestDB(t)
+
+	user := models.User{Email: "a@example.com", Password: "hash", Name: "A"}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	episode := models.SymptomEpisode{
+		UserID:      user.ID,
+		SymptomType: "physical",
+		SymptomName: "Sakit kepala",
+		StartedAt:   time.Now().Add(-24 * time.Hour),
+	}
+	episode.SetTriggers([]string{"kurang tidur", "kopi"})
+	if err := db.Create(&episode).Error; err != nil {
+		t.Fatalf("create episode: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := WriteArchive(&buf, db, user.ID); err != nil {
+		t.Fatalf("write archive: %v", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("read archive: %v", err)
+	}
+
+	var episodes []map[string]interface{}
+	if err := json.Unmarshal(readZipFile(t, zr, "symptom_episodes.json"), &episodes); err != nil {
+		t.Fatalf("decode symptom_episodes.json: %v", err)
+	}
+	if len(episodes) != 1 {
+		t.Fatalf("%d episodes in JSON, want 1", len(episodes))
+	}
+	if got := episodes[0]["triggers_text"]; got != "kurang tidur\nkopi" {
+		t.Errorf("JSON triggers_text = %q, want both triggers", got)
+	}
+
+	records, err := csv.NewReader(bytes.NewReader(readZipFile(t, zr, "symptom_episodes.csv"))).ReadAll()
+	if err != nil {
+		t.Fatalf("decode symptom_episodes.csv: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("%d CSV rows, want header and 1 episode", len(records))
+	}
+	column := -1
+	for i, name := range records[0] {
+		if name == "triggers_text" {
+			column = i
+		}
+	}
+	if column < 0 {
+		t.Fatalf("CSV columns %v have no triggers_text", records[0])
+	}
+	if got := records[1][column]; got != "kurang tidur\nkopi" {
+		t.Errorf("CSV triggers_text = %q, want both triggers", got)
+	}
+}
@@ -0,0 +1,158 @@
+package export
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"health-tracker/audit"
+	"health-tracker/config"
+	"health-tracker/database"
+	"health-tracker/mailer"
+	"health-tracker/models"
+	"health-tracker/utils"
+)
+
+// cleanupInterval is how often expired archives are removed from disk
+const cleanupInterval = time.Hour
+
+// StartWorker resumes jobs interrupted by a restart and periodically
+// deletes expired archives
+func StartWorker() {
+	if err := os.MkdirAll(config.AppConfig.ExportDir, 0o700); err != nil {
+		log.Printf("Failed to create export directory %s: %v", config.AppConfig.ExportDir, err)
+	}
+
+	var pending []models.DataExport
+	database.DB.Where("status IN ?", []string{models.ExportStatusPending, models.ExportStatusProcessing}).Find(&pending)
+	for _, job := range pending {
+		go Run(job.ID)
+	}
+
+	go func() {
+		for {
+			PurgeExpired()
+			time.Sleep(cleanupInterval)
+		}
+	}()
+}
+
+// Run builds the archive for a job, then emails the download link
+func Run(jobID uint) {
+	var job models.DataExport
+	if err := database.DB.First(&job, jobID).Error; err != nil {
+		return
+	}
+
+	database.DB.Model(&job).Update("status", models.ExportStatusProcessing)
+
+	path := filepath.Join(config.AppConfig.ExportDir, fmt.Sprintf("export-%d-%d.zip", job.UserID, job.ID))
+	manifest, size, err := writeFile(path, job.UserID)
+	if err != nil {
+		log.Printf("Export job %d failed: %v", job.ID, err)
+		os.Remove(path)
+		database.DB.Model(&job).Updates(map[string]interface{}{
+			"status": models.ExportStatusFailed,
+			"error":  "Export could not be generated",
+		})
+		return
+	}
+
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		os.Remove(path)
+		database.DB.Model(&job).Update("status", models.ExportStatusFailed)
+		return
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(LinkExpiry())
+	database.DB.Model(&job).Updates(map[string]interface{}{
+		"status":              models.ExportStatusReady,
+		"records":             manifest.TotalRecords,
+		"size_bytes":          size,
+		"file_path":           path,
+		"download_token_hash": utils.HashToken(token),
+		"expires_at":          expiresAt,
+		"completed_at":        now,
+	})
+
+	audit.Append(database.DB, audit.Entry{
+		Action:       models.AuditExportCreated,
+		ActorID:      job.UserID,
+		TargetUserID: job.UserID,
+		ResourceType: "data_export",
+		ResourceID:   strconv.FormatUint(uint64(job.ID), 10),
+		Details:      audit.Details{"records": manifest.TotalRecords, "size_bytes": size, "expires_at": expiresAt},
+	})
+
+	if err := notifyReady(job.UserID, token); err != nil {
+		log.Printf("Failed to send export email for job %d: %v", job.ID, err)
+	}
+}
+
+func writeFile(path string, userID uint) (*Manifest, int64, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+
+	manifest, err := WriteArchive(f, database.DB, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, 0, err
+	}
+	return manifest, info.Size(), f.Close()
+}
+
+func notifyReady(userID uint, token string) error {
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		return err
+	}
+
+	// The archive is served by the API itself, not by a frontend page
+	link := fmt.Sprintf("%s/api/account/export/download?token=%s", config.AppConfig.BackendURL, url.QueryEscape(token))
+	return mailer.AppMailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Ekspor Data Health Tracker Siap",
+		Body: fmt.Sprintf("Halo %s,\n\nEkspor data akun Anda sudah siap.\n"+
+			"Unduh melalui link berikut (berlaku %d jam):\n\n%s\n\n"+
+			"Jika Anda tidak meminta ekspor ini, segera ganti password Anda.",
+			user.Name, config.AppConfig.ExportLinkExpiryHours, link),
+	})
+}
+
+// LinkExpiry is how long a finished archive can be downloaded
+func LinkExpiry() time.Duration {
+	return time.Duration(config.AppConfig.ExportLinkExpiryHours) * time.Hour
+}
+
+// PurgeExpired deletes archives whose download link has expired
+func PurgeExpired() {
+	var expired []models.DataExport
+	database.DB.Where("status = ? AND expires_at < ?", models.ExportStatusReady, time.Now()).Find(&expired)
+
+	for _, job := range expired {
+		if job.FilePath != "" {
+			if err := os.Remove(job.FilePath); err != nil && !os.IsNotExist(err) {
+				log.Printf("Failed to delete expired export %s: %v", job.FilePath, err)
+				continue
+			}
+		}
+		database.DB.Model(&job).Updates(map[string]interface{}{
+			"status":              models.ExportStatusExpired,
+			"file_path":           "",
+			"download_token_hash": "",
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// table is a flat view of a slice of models: one column per exported,
+// JSON-visible scalar field. Fields tagged json:"-" (password hashes,
+// secrets) and nested associations are left out.
+type table struct {
+	Columns []string
+	Rows    [][]interface{}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// newTable flattens a slice of structs into a table
+func newTable(slice interface{}) table {
+	v := reflect.ValueOf(slice)
+	elemType := v.Type().Elem()
+
+	var t table
+	var fields []int
+	for i := 0; i < elemType.NumField(); i++ {
+		f := elemType.Field(i)
+		name, ok := columnName(f)
+		if !ok {
+			continue
+		}
+		t.Columns = append(t.Columns, name)
+		fields = append(fields, i)
+	}
+
+	for i := 0; i < v.Len(); i++ {
+		elem := v.Index(i)
+		row := make([]interface{}, len(fields))
+		for j, idx := range fields {
+			row[j] = elem.Field(idx).Interface()
+		}
+		t.Rows = append(t.Rows, row)
+	}
+
+	return t
+}
+
+// columnName returns the JSON name of a field, or false if the field is
+// hidden from JSON or is not a scalar
+func columnName(f reflect.StructField) (string, bool) {
+	if !f.IsExported() {
+		return "", false
+	}
+
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	name := strings.Split(tag, ",")[0]
+	if name == "" {
+		name = f.Name
+	}
+
+	ft := f.Type
+	if ft.Kind() == reflect.Ptr {
+		ft = ft.Elem()
+	}
+	switch ft.Kind() {
+	case reflect.Struct:
+		return name, ft == timeType
+	case reflect.Slice, reflect.Map, reflect.Array, reflect.Interface:
+		return "", false
+	}
+	return name, true
+}
+
+// csvRecord formats a row for CSV
+func csvRecord(row []interface{}) []string {
+	record := make([]string, len(row))
+	for i, value := range row {
+		record[i] = formatValue(value)
+	}
+	return record
+}
+
+func formatValue(value interface{}) string {
+	v := reflect.ValueOf(value)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+
+	switch x := v.Interface().(type) {
+	case time.Time:
+		if x.IsZero() {
+			return ""
+		}
+		return x.UTC().Format(time.RFC3339)
+	case float32:
+		return strconv.FormatFloat(float64(x), 'f', -1, 32)
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	default:
+		return fmt.Sprint(x)
+	}
+}
+
+// MarshalJSON writes the rows as an array of objects, keeping column order
+func (t table) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i, row := range t.Rows {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteByte('{')
+		for j, col := range t.Columns {
+			if j > 0 {
+				buf.WriteByte(',')
+			}
+			key, _ := json.Marshal(col)
+			value, err := json.Marshal(row[j])
+			if err != nil {
+				return nil, err
+			}
+			buf.Write(key)
+			buf.WriteByte(':')
+			buf.Write(value)
+		}
+		buf.WriteByte('}')
+	}
+	buf.WriteByte(']')
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"health-tracker/audit"
+	"health-tracker/config"
+	"health-tracker/database"
+	"health-tracker/export"
+	"health-tracker/mailer"
+	"health-tracker/models"
+	"health-tracker/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ExportAccount downloads everything the user has stored as a ZIP of JSON
+// and CSV files. Large accounts, or ?async=true, get a background job
+// whose download link is emailed when ready.
+func ExportAccount(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	records, err := export.CountRecords(database.DB, userID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to prepare export")
+		return
+	}
+
+	if c.Query("async") != "true" && records <= int64(config.AppConfig.ExportSyncMaxRecords) {
+		var buf bytes.Buffer
+		if _, err := export.WriteArchive(&buf, database.DB, userID); err != nil {
+			log.Printf("Export for user %d failed: %v", userID, err)
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to generate export")
+			return
+		}
+
+		audit.Record(c, audit.Entry{
+			Action:       models.AuditExportDownloaded,
+			TargetUserID: userID,
+			Details:      audit.Details{"records": records},
+		})
+
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, exportFileName(time.Now())))
+		c.Data(http.StatusOK, "application/zip", buf.Bytes())
+		return
+	}
+
+	// Reuse a job that is still running instead of starting another one
+	var job models.DataExport
+	result := database.DB.Where("user_id = ? AND status IN ?", userID,
+		[]string{models.ExportStatusPending, models.ExportStatusProcessing}).First(&job)
+	if result.Error != nil {
+		job = models.DataExport{UserID: userID, Status: models.ExportStatusPending, Records: records}
+		if err := database.DB.Create(&job).Error; err != nil {
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to start export")
+			return
+		}
+		go export.Run(job.ID)
+
+		audit.Record(c, audit.Entry{
+			Action:       models.AuditExportRequested,
+			TargetUserID: userID,
+			ResourceType: "data_export",
+			ResourceID:   strconv.FormatUint(uint64(job.ID), 10),
+			Details:      audit.Details{"records": records},
+		})
+	}
+
+	utils.SuccessResponse(c, http.StatusAccepted, "Export is being prepared, a download link will be emailed to you", job)
+}
+
+// GetExports lists the user's background export jobs
+func GetExports(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var jobs []models.DataExport
+	database.DB.Where("user_id = ?", userID).Order("created_at desc").Limit(20).Find(&jobs)
+
+	utils.SuccessResponse(c, http.StatusOK, "Exports retrieved", jobs)
+}
+
+// DownloadExport downloads a finished export of the signed-in user
+func DownloadExport(c *gin.Context) {
+	userID := c.GetUint("userID")
+	jobID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid export ID")
+		return
+	}
+
+	var job models.DataExport
+	if result := database.DB.Where("id = ? AND user_id = ?", jobID, userID).First(&job); result.Error != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Export not found")
+		return
+	}
+
+	serveExport(c, job)
+}
+
+// DownloadExportByToken downloads a finished export with the emailed link
+func DownloadExportByToken(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Token is required")
+		return
+	}
+
+	var job models.DataExport
+	if result := database.DB.Where("download_token_hash = ?", utils.HashToken(token)).First(&job); result.Error != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Invalid or expired download link")
+		return
+	}
+
+	serveExport(c, job)
+}
+
+func serveExport(c *gin.Context, job models.DataExport) {
+	if job.Status != models.ExportStatusReady || job.ExpiresAt == nil || time.Now().After(*job.ExpiresAt) {
+		utils.ErrorResponse(c, http.StatusNotFound, "Export is not ready or has expired")
+		return
+	}
+
+	if _, err := os.Stat(job.FilePath); err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Export is not ready or has expired")
+		return
+	}
+
+	// Emailed links carry no session, so the actor is unknown
+	entry := audit.Entry{
+		Action:       models.AuditExportDownloaded,
+		TargetUserID: job.UserID,
+		ResourceType: "data_export",
+		ResourceID:   strconv.FormatUint(uint64(job.ID), 10),
+	}
+	if c.GetUint("userID") == 0 {
+		entry.Details = audit.Details{"via": "email_link"}
+	}
+	audit.Record(c, entry)
+
+	c.FileAttachment(job.FilePath, exportFileName(job.CreatedAt))
+}
+
+// DeleteAccount schedules the account for permanent deletion after the
+// grace period and signs out every session. Logging in again cancels it.
+func DeleteAccount(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var req models.DeleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	var user models.User
+	if result := database.DB.First(&user, userID); result.Error != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "User not found")
+		return
+	}
+
+	if !utils.CheckPassword(req.Password, user.Password) {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid password")
+		return
+	}
+
+	if user.TwoFactorEnabled && !verifySecondFactor(&user, req.Code) {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid authentication code")
+		return
+	}
+
+	graceDays := config.AppConfig.AccountDeletionGraceDays
+	scheduledAt := time.Now().AddDate(0, 0, graceDays)
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Update("deletion_scheduled_at", scheduledAt).Error; err != nil {
+			return err
+		}
+		return revokeUserSessions(tx, user.ID, models.SessionRevokedAccountDelete)
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to schedule account deletion")
+		return
+	}
+
+	audit.Record(c, audit.Entry{
+		Action:       models.AuditDeletionScheduled,
+		TargetUserID: user.ID,
+		Details:      audit.Details{"scheduled_at": scheduledAt},
+	})
+
+	if err := mailer.AppMailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Akun Health Tracker Akan Dihapus",
+		Body: fmt.Sprintf("Halo %s,\n\nAkun Anda beserta seluruh datanya akan dihapus permanen pada %s.\n"+
+			"Untuk membatalkan, cukup login kembali sebelum tanggal tersebut.\n\n"+
+			"Jika Anda tidak meminta penghapusan ini, segera login dan ganti password Anda.",
+			user.Name, scheduledAt.Format("02 January 2006 15:04 MST")),
+	}); err != nil {
+		log.Printf("Failed to send account deletion email to user %d: %v", user.ID, err)
+	}
+
+	utils.SuccessResponse(c, http.StatusOK,
+		fmt.Sprintf("Account will be permanently deleted in %d days, log in again to cancel", graceDays),
+		gin.H{"deletion_scheduled_at": scheduledAt})
+}
+
+// cancelAccountDeletion clears a pending deletion when the user signs in again
+func cancelAccountDeletion(user *models.User) error {
+	if user.DeletionScheduledAt == nil {
+		return nil
+	}
+	if err := database.DB.Model(user).Update("deletion_scheduled_at", nil).Error; err != nil {
+		return err
+	}
+	log.Printf("Account deletion for user %d cancelled by login", user.ID)
+	audit.Append(database.DB, audit.Entry{
+		Action:       models.AuditDeletionCancelled,
+		ActorID:      user.ID,
+		TargetUserID: user.ID,
+	})
+	user.DeletionScheduledAt = nil
+	return nil
+}
+
+func exportFileName(t time.Time) string {
+	return "health-tracker-export-" + t.Format("20060102") + ".zip"
+}
@@ -0,0 +1,338 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"health-tracker/audit"
+	"health-tracker/database"
+	"health-tracker/models"
+	"health-tracker/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// AdminListUsers returns users, optionally filtered by email/name and role
+func AdminListUsers(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	query := database.DB.Model(&models.User{})
+	if q := c.Query("q"); q != "" {
+		pattern := "%" + q + "%"
+		query = query.Where("email LIKE ? OR name LIKE ?", pattern, pattern)
+	}
+	if role := c.Query("role"); role != "" {
+		query = query.Where("role = ?", role)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var users []models.User
+	query.Order("id asc").Offset((page - 1) * limit).Limit(limit).Find(&users)
+
+	utils.SuccessResponse(c, http.StatusOK, "Users retrieved", gin.H{
+		"users": users,
+		"total": total,
+		"page":  page,
+		"limit": limit,
+	})
+}
+
+// AdminUpdateUserRole changes a user's role and signs them out so the
+// new role is applied to their next token
+func AdminUpdateUserRole(c *gin.Context) {
+	adminID := c.GetUint("userID")
+	targetID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	var req models.UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+	if !models.IsValidRole(req.Role) {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid role")
+		return
+	}
+
+	// Prevent admins from locking themselves out
+	if uint(targetID) == adminID {
+		utils.ErrorResponse(c, http.StatusBadRequest, "You cannot change your own role")
+		return
+	}
+
+	var user models.User
+	if result := database.DB.First(&user, targetID); result.Error != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "User not found")
+		return
+	}
+
+	oldRole := user.Role
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Update("role", req.Role).Error; err != nil {
+			return err
+		}
+		return revokeUserSessions(tx, user.ID, models.SessionRevokedRoleChange)
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update role")
+		return
+	}
+
+	audit.Record(c, audit.Entry{
+		Action:       models.AuditAdminRoleUpdated,
+		TargetUserID: user.ID,
+		Details:      audit.Details{"old_role": oldRole, "new_role": req.Role},
+	})
+
+	utils.SuccessResponse(c, http.StatusOK, "Role updated", user)
+}
+
+// AdminCreateArticle publishes a new article
+func AdminCreateArticle(c *gin.Context) {
+	var req models.ArticleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	article := models.Article{
+		Title:    req.Title,
+		Content:  req.Content,
+		Summary:  req.Summary,
+		Category: req.Category,
+		ImageURL: req.ImageURL,
+		ReadTime: req.ReadTime,
+	}
+
+	if result := database.DB.Create(&article); result.Error != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to create article")
+		return
+	}
+
+	audit.Record(c, audit.Entry{
+		Action:       models.AuditAdminArticleCreated,
+		ResourceType: "article",
+		ResourceID:   strconv.FormatUint(uint64(article.ID), 10),
+		Details:      audit.Details{"title": article.Title},
+	})
+
+	utils.SuccessResponse(c, http.StatusCreated, "Article created", article)
+}
+
+// AdminUpdateArticle replaces an article's content
+func AdminUpdateArticle(c *gin.Context) {
+	var article models.Article
+	if result := database.DB.First(&article, c.Param("id")); result.Error != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Article not found")
+		return
+	}
+
+	var req models.ArticleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	article.Title = req.Title
+	article.Content = req.Content
+	article.Summary = req.Summary
+	article.Category = req.Category
+	article.ImageURL = req.ImageURL
+	article.ReadTime = req.ReadTime
+
+	if result := database.DB.Save(&article); result.Error != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update article")
+		return
+	}
+
+	audit.Record(c, audit.Entry{
+		Action:       models.AuditAdminArticleUpdated,
+		ResourceType: "article",
+		ResourceID:   strconv.FormatUint(uint64(article.ID), 10),
+		Details:      audit.Details{"title": article.Title},
+	})
+
+	utils.SuccessResponse(c, http.StatusOK, "Article updated", article)
+}
+
+// AdminDeleteArticle removes an article
+func AdminDeleteArticle(c *gin.Context) {
+	result := database.DB.Delete(&models.Article{}, c.Param("id"))
+	if result.Error != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to delete article")
+		return
+	}
+	if result.RowsAffected == 0 {
+		utils.ErrorResponse(c, http.StatusNotFound, "Article not found")
+		return
+	}
+
+	audit.Record(c, audit.Entry{
+		Action:       models.AuditAdminArticleDeleted,
+		ResourceType: "article",
+		ResourceID:   c.Param("id"),
+	})
+
+	utils.SuccessResponse(c, http.StatusOK, "Article deleted", nil)
+}
+
+// ModerateDeletePost removes any forum post with its comments and likes
+func ModerateDeletePost(c *gin.Context) {
+	var post models.Post
+	if result := database.DB.First(&post, c.Param("id")); result.Error != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Post not found")
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("post_id = ?", post.ID).Delete(&models.Comment{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("post_id = ?", post.ID).Delete(&models.Like{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&post).Error
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to delete post")
+		return
+	}
+
+	audit.Record(c, audit.Entry{
+		Action:       models.AuditAdminPostDeleted,
+		TargetUserID: post.UserID,
+		ResourceType: "post",
+		ResourceID:   strconv.FormatUint(uint64(post.ID), 10),
+		Details:      audit.Details{"title": post.Title},
+	})
+
+	utils.SuccessResponse(c, http.StatusOK, "Post deleted", nil)
+}
+
+// ModerateDeleteComment removes any forum comment
+func ModerateDeleteComment(c *gin.Context) {
+	var comment models.Comment
+	if result := database.DB.First(&comment, c.Param("id")); result.Error != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Comment not found")
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&comment).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Post{}).Where("id = ? AND comments_count > 0", comment.PostID).
+			Update("comments_count", gorm.Expr("comments_count - 1")).Error
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to delete comment")
+		return
+	}
+
+	audit.Record(c, audit.Entry{
+		Action:       models.AuditAdminCommentDeleted,
+		TargetUserID: comment.UserID,
+		ResourceType: "comment",
+		ResourceID:   strconv.FormatUint(uint64(comment.ID), 10),
+		Details:      audit.Details{"post_id": comment.PostID},
+	})
+
+	utils.SuccessResponse(c, http.StatusOK, "Comment deleted", nil)
+}
+
+// AdminCreateSymptomTemplate adds a symptom to the selectable list
+func AdminCreateSymptomTemplate(c *gin.Context) {
+	var req models.SymptomTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	template := models.SymptomTemplate{
+		SymptomType: req.SymptomType,
+		SymptomName: req.SymptomName,
+		Description: req.Description,
+	}
+
+	if result := database.DB.Create(&template); result.Error != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to create symptom template")
+		return
+	}
+
+	audit.Record(c, audit.Entry{
+		Action:       models.AuditAdminSymptomCreated,
+		ResourceType: "symptom_template",
+		ResourceID:   strconv.FormatUint(uint64(template.ID), 10),
+		Details:      audit.Details{"name": template.SymptomName},
+	})
+
+	utils.SuccessResponse(c, http.StatusCreated, "Symptom template created", template)
+}
+
+// AdminUpdateSymptomTemplate edits a symptom template
+func AdminUpdateSymptomTemplate(c *gin.Context) {
+	var template models.SymptomTemplate
+	if result := database.DB.First(&template, c.Param("id")); result.Error != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Symptom template not found")
+		return
+	}
+
+	var req models.SymptomTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	template.SymptomType = req.SymptomType
+	template.SymptomName = req.SymptomName
+	template.Description = req.Description
+
+	if result := database.DB.Save(&template); result.Error != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update symptom template")
+		return
+	}
+
+	audit.Record(c, audit.Entry{
+		Action:       models.AuditAdminSymptomUpdated,
+		ResourceType: "symptom_template",
+		ResourceID:   strconv.FormatUint(uint64(template.ID), 10),
+		Details:      audit.Details{"name": template.SymptomName},
+	})
+
+	utils.SuccessResponse(c, http.StatusOK, "Symptom template updated", template)
+}
+
+// AdminDeleteSymptomTemplate removes a symptom template. Logged symptoms
+// keep their name, so history is unaffected.
+func AdminDeleteSymptomTemplate(c *gin.Context) {
+	result := database.DB.Delete(&models.SymptomTemplate{}, c.Param("id"))
+	if result.Error != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to delete symptom template")
+		return
+	}
+	if result.RowsAffected == 0 {
+		utils.ErrorResponse(c, http.StatusNotFound, "Symptom template not found")
+		return
+	}
+
+	audit.Record(c, audit.Entry{
+		Action:       models.AuditAdminSymptomDeleted,
+		ResourceType: "symptom_template",
+		ResourceID:   c.Param("id"),
+	})
+
+	utils.SuccessResponse(c, http.StatusOK, "Symptom template deleted", nil)
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"health-tracker/anomaly"
+	"health-tracker/database"
+	"health-tracker/models"
+	"health-tracker/units"
+	"health-tracker/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// dashboardAlertDays is how far back open alerts are shown on the dashboard
+const dashboardAlertDays = 30
+
+// GetHealthAlerts lists the user's health alerts, newest first. Filters:
+// status (open, acknowledged or all; default open) and metric, with page
+// and limit.
+func GetHealthAlerts(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	query := database.DB.Model(&models.HealthAlert{}).Where("user_id = ?", userID)
+
+	switch c.DefaultQuery("status", "open") {
+	case "open":
+		query = query.Where("acknowledged_at IS NULL")
+	case "acknowledged":
+		query = query.Where("acknowledged_at IS NOT NULL")
+	case "all":
+	default:
+		utils.ErrorResponse(c, http.StatusBadRequest, "status must be open, acknowledged or all")
+		return
+	}
+	if metric := c.Query("metric"); metric != "" {
+		query = query.Where("metric = ?", metric)
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "30"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 200 {
+		limit = 30
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var alerts []models.HealthAlert
+	query.Order("observed_on desc, id desc").Offset((page - 1) * limit).Limit(limit).Find(&alerts)
+
+	utils.SuccessResponse(c, http.StatusOK, "Health alerts retrieved", gin.H{
+		"alerts": alertViews(alerts, loadUnits(userID)),
+		"total":  total,
+		"page":   page,
+		"limit":  limit,
+	})
+}
+
+// CheckHealthAlerts runs anomaly detection for the user now instead of
+// waiting for the background job
+func CheckHealthAlerts(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	created, err := anomaly.Detect(userID, time.Now())
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to check health data")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Health data checked", gin.H{
+		"new_alerts": alertViews(created, loadUnits(userID)),
+	})
+}
+
+// AcknowledgeHealthAlert marks an alert as seen
+func AcknowledgeHealthAlert(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var alert models.HealthAlert
+	if result := database.DB.Where("id = ? AND user_id = ?", c.Param("id"), userID).First(&alert); result.Error != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Health alert not found")
+		return
+	}
+
+	if alert.AcknowledgedAt == nil {
+		now := time.Now()
+		alert.AcknowledgedAt = &now
+		if result := database.DB.Save(&alert); result.Error != nil {
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to acknowledge health alert")
+			return
+		}
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Health alert acknowledged", models.NewHealthAlertView(alert, loadUnits(userID)))
+}
+
+// loadOpenAlerts returns the user's unacknowledged alerts from the last
+// dashboardAlertDays days
+func loadOpenAlerts(userID uint, prefs units.Preferences) []models.HealthAlertView {
+	since := time.Now().AddDate(0, 0, -dashboardAlertDays).Format("2006-01-02")
+
+	var alerts []models.HealthAlert
+	database.DB.Where("user_id = ? AND acknowledged_at IS NULL AND observed_on >= ?", userID, since).
+		Order("observed_on desc, id desc").Find(&alerts)
+	return alertViews(alerts, prefs)
+}
+
+func alertViews(alerts []models.HealthAlert, prefs units.Preferences) []models.HealthAlertView {
+	views := make([]models.HealthAlertView, len(alerts))
+	for i, a := range alerts {
+		views[i] = models.NewHealthAlertView(a, prefs)
+	}
+	return views
+}
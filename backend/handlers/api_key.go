@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"health-tracker/audit"
+	"health-tracker/database"
+	"health-tracker/models"
+	"health-tracker/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxActiveAPIKeys caps how many usable keys one user can hold
+const maxActiveAPIKeys = 20
+
+// GetAPIKeys lists the user's active API keys
+func GetAPIKeys(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var keys []models.APIKey
+	database.DB.Where("user_id = ? AND revoked_at IS NULL", userID).Order("created_at desc").Find(&keys)
+
+	response := make([]models.APIKeyResponse, 0, len(keys))
+	for _, k := range keys {
+		if k.IsActive() {
+			response = append(response, apiKeyResponse(k))
+		}
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "API keys retrieved", response)
+}
+
+// CreateAPIKey issues a new API key. The key is only returned in this response.
+func CreateAPIKey(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var req models.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	scopes, ok := normalizeScopes(req.Scopes)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid scope, use <resource>:read or <resource>:write with resource one of: "+strings.Join(models.APIKeyResources, ", "))
+		return
+	}
+
+	var active int64
+	database.DB.Model(&models.APIKey{}).
+		Where("user_id = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", userID, time.Now()).
+		Count(&active)
+	if active >= maxActiveAPIKeys {
+		utils.ErrorResponse(c, http.StatusConflict, "Too many active API keys, revoke one first")
+		return
+	}
+
+	secret, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to generate API key")
+		return
+	}
+	key := models.APIKeyPrefix + secret
+
+	apiKey := models.APIKey{
+		UserID:  userID,
+		Name:    req.Name,
+		Prefix:  key[:len(models.APIKeyPrefix)+8],
+		KeyHash: utils.HashToken(key),
+		Scopes:  strings.Join(scopes, " "),
+	}
+	if req.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		apiKey.ExpiresAt = &expiresAt
+	}
+
+	if err := database.DB.Create(&apiKey).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to create API key")
+		return
+	}
+
+	audit.Record(c, audit.Entry{
+		Action:       models.AuditAPIKeyCreated,
+		TargetUserID: userID,
+		ResourceType: "api_key",
+		ResourceID:   strconv.FormatUint(uint64(apiKey.ID), 10),
+		Details:      audit.Details{"name": apiKey.Name, "scopes": scopes},
+	})
+
+	response := apiKeyResponse(apiKey)
+	response.Key = key
+
+	utils.SuccessResponse(c, http.StatusCreated, "API key created, copy it now because it will not be shown again", response)
+}
+
+// RevokeAPIKey permanently disables one of the user's API keys
+func RevokeAPIKey(c *gin.Context) {
+	userID := c.GetUint("userID")
+	keyID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid API key ID")
+		return
+	}
+
+	result := database.DB.Model(&models.APIKey{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", keyID, userID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to revoke API key")
+		return
+	}
+	if result.RowsAffected == 0 {
+		utils.ErrorResponse(c, http.StatusNotFound, "API key not found")
+		return
+	}
+
+	audit.Record(c, audit.Entry{
+		Action:       models.AuditAPIKeyRevoked,
+		TargetUserID: userID,
+		ResourceType: "api_key",
+		ResourceID:   c.Param("id"),
+	})
+
+	utils.SuccessResponse(c, http.StatusOK, "API key revoked", nil)
+}
+
+// normalizeScopes validates, deduplicates and sorts the requested scopes
+func normalizeScopes(requested []string) ([]string, bool) {
+	seen := make(map[string]bool)
+	scopes := make([]string, 0, len(requested))
+	for _, s := range requested {
+		s = strings.ToLower(strings.TrimSpace(s))
+		if !models.IsValidScope(s) {
+			return nil, false
+		}
+		if !seen[s] {
+			seen[s] = true
+			scopes = append(scopes, s)
+		}
+	}
+	sort.Strings(scopes)
+	return scopes, true
+}
+
+func apiKeyResponse(k models.APIKey) models.APIKeyResponse {
+	return models.APIKeyResponse{
+		ID:         k.ID,
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		Scopes:     k.ScopeList(),
+		LastUsedAt: k.LastUsedAt,
+		ExpiresAt:  k.ExpiresAt,
+		CreatedAt:  k.CreatedAt,
+	}
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"health-tracker/audit"
+	"health-tracker/database"
+	"health-tracker/models"
+	"health-tracker/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetAccountAuditLog shows the user every audited action they performed
+// or that touched their data, such as family members viewing their health
+func GetAccountAuditLog(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	query := database.DB.Model(&models.AuditLog{}).Where("actor_id = ? OR target_user_id = ?", userID, userID)
+	query, ok := filterAuditLog(c, query)
+	if !ok {
+		return
+	}
+
+	respondAuditLog(c, query)
+}
+
+// AdminGetAuditLog searches the whole audit log. Filters: actor_id,
+// target_user_id, action (prefix match, e.g. "admin."), resource_type,
+// from and to (YYYY-MM-DD).
+func AdminGetAuditLog(c *gin.Context) {
+	query := database.DB.Model(&models.AuditLog{})
+
+	if actorID := c.Query("actor_id"); actorID != "" {
+		query = query.Where("actor_id = ?", actorID)
+	}
+	if targetID := c.Query("target_user_id"); targetID != "" {
+		query = query.Where("target_user_id = ?", targetID)
+	}
+	if resourceType := c.Query("resource_type"); resourceType != "" {
+		query = query.Where("resource_type = ?", resourceType)
+	}
+
+	query, ok := filterAuditLog(c, query)
+	if !ok {
+		return
+	}
+
+	audit.Record(c, audit.Entry{
+		Action:  models.AuditAdminAuditLogViewed,
+		Details: audit.Details{"filters": c.Request.URL.RawQuery},
+	})
+
+	respondAuditLog(c, query)
+}
+
+// filterAuditLog applies the action and date range filters shared by both endpoints
+func filterAuditLog(c *gin.Context, query *gorm.DB) (*gorm.DB, bool) {
+	if action := c.Query("action"); action != "" {
+		query = query.Where("action LIKE ?", strings.ReplaceAll(action, "%", "")+"%")
+	}
+
+	if from := c.Query("from"); from != "" {
+		t, err := time.Parse("2006-01-02", from)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid from date, use YYYY-MM-DD")
+			return nil, false
+		}
+		query = query.Where("created_at >= ?", t)
+	}
+	if to := c.Query("to"); to != "" {
+		t, err := time.Parse("2006-01-02", to)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid to date, use YYYY-MM-DD")
+			return nil, false
+		}
+		query = query.Where("created_at < ?", t.AddDate(0, 0, 1))
+	}
+
+	return query, true
+}
+
+func respondAuditLog(c *gin.Context, query *gorm.DB) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 200 {
+		limit = 50
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var entries []models.AuditLog
+	query.Order("created_at desc, id desc").Offset((page - 1) * limit).Limit(limit).Find(&entries)
+
+	utils.SuccessResponse(c, http.StatusOK, "Audit log retrieved", gin.H{
+		"entries": entries,
+		"total":   total,
+		"page":    page,
+		"limit":   limit,
+	})
+}
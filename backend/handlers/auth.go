@@ -1,13 +1,22 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
+	"net/url"
+	"time"
 
+	"health-tracker/audit"
+	"health-tracker/config"
 	"health-tracker/database"
+	"health-tracker/mailer"
 	"health-tracker/models"
 	"health-tracker/utils"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // Register creates a new user account
@@ -38,6 +47,7 @@ func Register(c *gin.Context) {
 		Email:    req.Email,
 		Password: hashedPassword,
 		Name:     req.Name,
+		Role:     models.RoleUser,
 	}
 
 	if result := database.DB.Create(&user); result.Error != nil {
@@ -45,17 +55,19 @@ func Register(c *gin.Context) {
 		return
 	}
 
-	// Generate token
-	token, err := utils.GenerateToken(user.ID, user.Email)
+	// Registration still succeeds if the email cannot be sent, the user can resend it
+	if err := sendVerificationEmail(user); err != nil {
+		log.Printf("Failed to send verification email to user %d: %v", user.ID, err)
+	}
+
+	// Start session
+	response, err := startSession(c, user)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to generate token")
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusCreated, "Registration successful", models.LoginResponse{
-		Token: token,
-		User:  user,
-	})
+	utils.SuccessResponse(c, http.StatusCreated, "Registration successful", response)
 }
 
 // Login authenticates user and returns JWT
@@ -70,27 +82,48 @@ func Login(c *gin.Context) {
 	// Find user by email
 	var user models.User
 	if result := database.DB.Where("email = ?", req.Email).First(&user); result.Error != nil {
-		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid email or password")
+		rejectUnknownEmail(c, req.Email)
+		return
+	}
+
+	// Locked accounts are rejected before the password is checked
+	if rejectLockedAccount(c, &user) {
 		return
 	}
 
 	// Check password
 	if !utils.CheckPassword(req.Password, user.Password) {
+		registerLoginFailure(c, &user, models.LoginFailureBadPassword)
 		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid email or password")
 		return
 	}
 
-	// Generate token
-	token, err := utils.GenerateToken(user.ID, user.Email)
+	// Second step: the client exchanges this token plus a TOTP code at /auth/2fa/verify
+	if user.TwoFactorEnabled {
+		challengeToken, err := utils.GenerateTwoFactorToken(user.ID, user.Email)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to generate token")
+			return
+		}
+
+		utils.SuccessResponse(c, http.StatusOK, "Two-factor authentication required", models.TwoFactorChallengeResponse{
+			TwoFactorRequired: true,
+			TwoFactorToken:    challengeToken,
+			ExpiresIn:         config.AppConfig.TwoFactorTokenExpiryMinutes * 60,
+		})
+		return
+	}
+
+	// Start session
+	response, err := startSession(c, user)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to generate token")
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Login successful", models.LoginResponse{
-		Token: token,
-		User:  user,
-	})
+	registerLoginSuccess(c, &user)
+
+	utils.SuccessResponse(c, http.StatusOK, "Login successful", response)
 }
 
 // GetCurrentUser returns the authenticated user's profile
@@ -103,6 +136,8 @@ func GetCurrentUser(c *gin.Context) {
 		return
 	}
 
+	refreshActivityLevel(&user)
+
 	utils.SuccessResponse(c, http.StatusOK, "User profile retrieved", user)
 }
 
@@ -129,40 +164,96 @@ func UpdateProfile(c *gin.Context) {
 	if !req.BirthDate.IsZero() {
 		user.BirthDate = req.BirthDate
 	}
+	if req.Sex != "" {
+		user.Sex = req.Sex
+	}
 	if req.HeightCm > 0 {
 		user.HeightCm = req.HeightCm
 	}
 	if req.WeightKg > 0 {
 		user.WeightKg = req.WeightKg
 	}
-	if req.ActivityLevel != "" {
+	// A workout-derived activity level is not overwritten by hand
+	if req.ActivityLevel != "" && user.ActivityLevelSource != models.ActivitySourceWorkouts {
 		user.ActivityLevel = req.ActivityLevel
 	}
+	if req.ShareAlertsWithFamily != nil {
+		user.ShareAlertsWithFamily = *req.ShareAlertsWithFamily
+	}
 
 	database.DB.Save(&user)
 
 	utils.SuccessResponse(c, http.StatusOK, "Profile updated", user)
 }
 
-// ResetPasswordRequest represents the request body for password reset
-type ResetPasswordRequest struct {
-	Email       string `json:"email" binding:"required,email"`
-	NewPassword string `json:"new_password" binding:"required,min=6"`
+// ForgotPassword emails a single-use password reset link.
+// The response is the same whether or not the email is registered.
+func ForgotPassword(c *gin.Context) {
+	var req models.ForgotPasswordRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	const genericMessage = "Jika email terdaftar, link reset password telah dikirim"
+
+	var user models.User
+	if result := database.DB.Where("email = ?", req.Email).First(&user); result.Error != nil {
+		utils.SuccessResponse(c, http.StatusOK, genericMessage, nil)
+		return
+	}
+
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to generate reset token")
+		return
+	}
+
+	// Only the newest link stays valid
+	database.DB.Where("user_id = ? AND used_at IS NULL", user.ID).Delete(&models.PasswordResetToken{})
+
+	expiry := time.Duration(config.AppConfig.PasswordResetExpiryMinutes) * time.Minute
+	resetToken := models.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().Add(expiry),
+	}
+
+	if result := database.DB.Create(&resetToken); result.Error != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to create reset token")
+		return
+	}
+
+	link := fmt.Sprintf("%s/reset-password?token=%s", config.AppConfig.FrontendURL, url.QueryEscape(token))
+	err = mailer.AppMailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Reset Password Health Tracker",
+		Body: fmt.Sprintf("Halo %s,\n\nKami menerima permintaan untuk mereset password akun Anda.\n"+
+			"Buka link berikut untuk membuat password baru (berlaku %d menit):\n\n%s\n\n"+
+			"Jika Anda tidak meminta reset password, abaikan email ini.",
+			user.Name, config.AppConfig.PasswordResetExpiryMinutes, link),
+	})
+	if err != nil {
+		log.Printf("Failed to send password reset email to user %d: %v", user.ID, err)
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, genericMessage, nil)
 }
 
-// ResetPassword resets user password by email verification
+// ResetPassword sets a new password using a token from ForgotPassword
 func ResetPassword(c *gin.Context) {
-	var req ResetPasswordRequest
+	var req models.ResetPasswordRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request: "+err.Error())
 		return
 	}
 
-	// Find user by email
-	var user models.User
-	if result := database.DB.Where("email = ?", req.Email).First(&user); result.Error != nil {
-		utils.ErrorResponse(c, http.StatusNotFound, "Email tidak ditemukan dalam sistem")
+	var resetToken models.PasswordResetToken
+	if result := database.DB.Where("token_hash = ? AND used_at IS NULL AND expires_at > ?",
+		utils.HashToken(req.Token), time.Now()).First(&resetToken); result.Error != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Token reset tidak valid atau sudah kedaluwarsa")
 		return
 	}
 
@@ -173,12 +264,47 @@ func ResetPassword(c *gin.Context) {
 		return
 	}
 
-	// Update password
-	user.Password = hashedPassword
-	if result := database.DB.Save(&user); result.Error != nil {
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// Claim the token first so concurrent requests cannot both use it
+		now := time.Now()
+		result := tx.Model(&models.PasswordResetToken{}).
+			Where("id = ? AND used_at IS NULL", resetToken.ID).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errTokenAlreadyUsed
+		}
+
+		if err := tx.Model(&models.User{}).Where("id = ?", resetToken.UserID).Updates(map[string]interface{}{
+			"password":              hashedPassword,
+			"failed_login_attempts": 0,
+			"locked_until":          nil,
+		}).Error; err != nil {
+			return err
+		}
+
+		// Sign out everywhere, the old password may have been compromised
+		return revokeUserSessions(tx, resetToken.UserID, models.SessionRevokedPasswordReset)
+	})
+
+	if errors.Is(err, errTokenAlreadyUsed) {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Token reset tidak valid atau sudah kedaluwarsa")
+		return
+	}
+	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update password")
 		return
 	}
 
+	audit.Record(c, audit.Entry{
+		Action:       models.AuditPasswordReset,
+		ActorID:      resetToken.UserID,
+		TargetUserID: resetToken.UserID,
+	})
+
 	utils.SuccessResponse(c, http.StatusOK, "Password berhasil direset", nil)
 }
+
+var errTokenAlreadyUsed = errors.New("token already used")
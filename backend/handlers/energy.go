@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"time"
+
+	"health-tracker/database"
+	"health-tracker/models"
+	"health-tracker/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// kcalPerKgBodyFat is the usual estimate for converting weight change
+// into an energy surplus or deficit
+const kcalPerKgBodyFat = 7700
+
+// calorieFactorsPerKg are rule-of-thumb daily energy needs in kcal per kg
+// of body weight, used when height is unknown and BMR cannot be computed
+var calorieFactorsPerKg = map[string]float64{
+	models.ActivitySedentary: 25,
+	models.ActivityLight:     30,
+	models.ActivityModerate:  35,
+	models.ActivityActive:    40,
+}
+
+// GetEnergyTargets returns BMR, TDEE and the daily calorie and
+// macronutrient targets derived from the profile and weight goal
+func GetEnergyTargets(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var user models.User
+	if result := database.DB.First(&user, userID); result.Error != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "User not found")
+		return
+	}
+	refreshActivityLevel(&user)
+
+	utils.SuccessResponse(c, http.StatusOK, "Energy targets retrieved", computeEnergyTargets(user))
+}
+
+// computeEnergyTargets derives energy needs with Mifflin-St Jeor and the
+// activity multiplier, then adjusts for the user's open weight goal.
+// Missing profile data is filled with defaults listed in Assumptions.
+func computeEnergyTargets(user models.User) models.EnergyTargets {
+	t := models.EnergyTargets{
+		Sex:           user.Sex,
+		ActivityLevel: user.ActivityLevel,
+	}
+
+	if _, ok := models.ActivityMultipliers[t.ActivityLevel]; !ok {
+		t.ActivityLevel = models.ActivitySedentary
+		t.Assumptions = append(t.Assumptions, "activity level unknown, using sedentary")
+	}
+	t.ActivityFactor = models.ActivityMultipliers[t.ActivityLevel]
+
+	var latest models.HealthData
+	database.DB.Where("user_id = ?", user.ID).Order("record_date desc, id desc").First(&latest)
+	t.WeightKg = latest.WeightKg
+	if t.WeightKg <= 0 {
+		t.WeightKg = user.WeightKg
+	}
+	if t.WeightKg <= 0 {
+		t.WeightKg = defaultWorkoutWeightKg
+		t.Assumptions = append(t.Assumptions, "weight unknown, using 70 kg")
+	}
+	t.HeightCm = latest.HeightCm
+	if t.HeightCm <= 0 {
+		t.HeightCm = user.HeightCm
+	}
+
+	if user.BirthDate.IsZero() {
+		t.AgeYears = 30
+		t.Assumptions = append(t.Assumptions, "birth date unknown, using age 30")
+	} else {
+		t.AgeYears = ageOn(user.BirthDate, time.Now())
+		if t.AgeYears < 18 {
+			t.Assumptions = append(t.Assumptions, "Mifflin-St Jeor is validated for adults; targets for under 18 are approximate")
+		}
+	}
+	if t.Sex == "" {
+		t.Assumptions = append(t.Assumptions, "sex unknown, using the average of the male and female equations")
+	}
+
+	if t.HeightCm > 0 {
+		t.Method = "mifflin_st_jeor"
+		t.BMR = math.Round(models.CalculateBMR(t.Sex, t.WeightKg, t.HeightCm, t.AgeYears))
+		t.TDEE = math.Round(t.BMR * t.ActivityFactor)
+	} else {
+		t.Method = "weight_based"
+		t.TDEE = math.Round(t.WeightKg * calorieFactorsPerKg[t.ActivityLevel])
+		t.Assumptions = append(t.Assumptions, "height unknown, estimating TDEE from weight only")
+	}
+
+	t.Goal = models.EnergyGoalMaintain
+	if goal, ok := openWeightGoal(user.ID); ok {
+		t.GoalID = &goal.ID
+		t.GoalWeightKg = goal.Target
+		t.Goal, t.DailyAdjustment = weightGoalAdjustment(t.WeightKg, goal)
+	}
+
+	calories := t.TDEE + t.DailyAdjustment
+	if minimum := models.MinimumCalories[t.Sex]; calories < minimum {
+		calories = minimum
+		t.Assumptions = append(t.Assumptions, "target raised to the safe minimum intake")
+	}
+	calories = math.Round(calories/10) * 10
+
+	// Protein per kg body weight, more when changing weight to preserve
+	// lean mass, capped at 35% of energy. Fat gets 25%, carbs the rest.
+	proteinPerKg := 1.2
+	if t.Goal != models.EnergyGoalMaintain {
+		proteinPerKg = 1.6
+	}
+	protein := math.Min(t.WeightKg*proteinPerKg, calories*0.35/4)
+	fat := calories * 0.25 / 9
+	carbs := (calories - protein*4 - fat*9) / 4
+
+	t.Target = roundNutrients(models.Nutrients{
+		Calories: calories,
+		ProteinG: protein,
+		CarbsG:   carbs,
+		FatG:     fat,
+		FiberG:   calories / 1000 * 14,
+		SodiumMg: 2000,
+	})
+	return t
+}
+
+// weightGoalAdjustment returns the goal direction and daily kcal change
+// needed to reach the goal weight by its deadline. Without a deadline it
+// aims for about 0.5 kg a week when losing and 0.25 kg when gaining.
+func weightGoalAdjustment(weightKg float64, goal models.Goal) (string, float64) {
+	diff := goal.Target - weightKg
+	if math.Abs(diff) <= 0.5 {
+		return models.EnergyGoalMaintain, 0
+	}
+
+	var perDay float64
+	if deadline, err := time.ParseInLocation("2006-01-02", goal.Deadline, time.Local); err == nil {
+		if days := time.Until(deadline).Hours() / 24; days >= 1 {
+			perDay = math.Abs(diff) * kcalPerKgBodyFat / days
+		}
+	}
+
+	if diff < 0 {
+		if perDay == 0 {
+			perDay = 500
+		}
+		return models.EnergyGoalLose, -math.Round(math.Max(250, math.Min(perDay, 1000)))
+	}
+	if perDay == 0 {
+		perDay = 300
+	}
+	return models.EnergyGoalGain, math.Round(math.Max(250, math.Min(perDay, 500)))
+}
+
+// ageOn returns the age in whole years on the given day
+func ageOn(birthDate, day time.Time) int {
+	age := day.Year() - birthDate.Year()
+	if day.Month() < birthDate.Month() || (day.Month() == birthDate.Month() && day.Day() < birthDate.Day()) {
+		age--
+	}
+	if age < 0 {
+		return 0
+	}
+	return age
+}
@@ -4,6 +4,8 @@ import (
 	"net/http"
 	"strconv"
 
+	"health-tracker/audit"
+	"health-tracker/config"
 	"health-tracker/database"
 	"health-tracker/models"
 	"health-tracker/utils"
@@ -28,6 +30,12 @@ func InviteFamilyMember(c *gin.Context) {
 		return
 	}
 
+	// Unverified accounts may belong to someone squatting the address
+	if config.AppConfig.RequireVerifiedEmail && !memberUser.EmailVerified {
+		utils.ErrorResponse(c, http.StatusBadRequest, "This user has not verified their email yet")
+		return
+	}
+
 	// Can't invite yourself
 	if memberUser.ID == userID {
 		utils.ErrorResponse(c, http.StatusBadRequest, "Cannot invite yourself")
@@ -195,14 +203,26 @@ func GetFamilyMemberHealth(c *gin.Context) {
 	var recentSymptoms []models.Symptom
 	database.DB.Where("user_id = ?", memberUserID).Order("logged_at desc").Limit(5).Find(&recentSymptoms)
 
+	bmiResult := classifyBMI(memberUserID, latestHealth)
+	prefs := loadUnits(userID)
+	latestView := models.NewHealthDataView(latestHealth, prefs)
 	response := models.FamilyHealthView{
 		MemberName:     memberUser.Name,
 		Relationship:   familyMember.Relationship,
-		LatestHealth:   &latestHealth,
-		BMICategory:    models.GetBMICategory(latestHealth.BMI),
+		LatestHealth:   &latestView,
+		BMICategory:    bmiResult.Category,
+		BMI:            bmiResult,
 		RecentSymptoms: recentSymptoms,
+		Alerts:         loadOpenAlerts(memberUserID, prefs),
 	}
 
+	audit.Record(c, audit.Entry{
+		Action:       models.AuditFamilyHealthViewed,
+		TargetUserID: memberUserID,
+		ResourceType: "family_member",
+		ResourceID:   strconv.FormatUint(uint64(familyMember.ID), 10),
+	})
+
 	utils.SuccessResponse(c, http.StatusOK, "Family member health retrieved", response)
 }
 
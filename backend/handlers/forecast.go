@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"health-tracker/config"
+	"health-tracker/database"
+	"health-tracker/models"
+	"health-tracker/units"
+	"health-tracker/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetWeightForecast returns the smoothed weight trend, the current rate
+// of change and, when there is a target, the projected date it is
+// reached. Query: goal_id (default: the newest open weight goal), target
+// (in the user's weight unit, instead of a goal) and window (days).
+func GetWeightForecast(c *gin.Context) {
+	userID := c.GetUint("userID")
+	prefs := loadUnits(userID)
+
+	window := config.AppConfig.WeightForecastWindowDays
+	if v := c.Query("window"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 7 || n > 180 {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid window, use 7 to 180 days")
+			return
+		}
+		window = n
+	}
+
+	var goal models.Goal
+	hasGoal := false
+	if id := c.Query("goal_id"); id != "" {
+		result := database.DB.Where("id = ? AND user_id = ? AND type = ?", id, userID, models.GoalTypeWeight).First(&goal)
+		if result.Error != nil {
+			utils.ErrorResponse(c, http.StatusNotFound, "Weight goal not found")
+			return
+		}
+		hasGoal = true
+	} else if c.Query("target") == "" {
+		goal, hasGoal = openWeightGoal(userID)
+	}
+
+	var targetKg float64
+	var deadline string
+	if hasGoal {
+		targetKg, deadline = goal.Target, goal.Deadline
+	}
+	if v := c.Query("target"); v != "" {
+		target, err := strconv.ParseFloat(v, 64)
+		if err == nil && target > 0 {
+			targetKg, err = units.ToKg(target, prefs.Weight)
+		}
+		if err != nil || target <= 0 {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid target weight")
+			return
+		}
+		deadline = c.Query("deadline")
+	}
+
+	now := time.Now()
+	forecast := models.ForecastWeight(loadWeightSamples(userID, now.AddDate(0, 0, -window)), now, window, targetKg, deadline)
+	if hasGoal {
+		forecast.GoalID = &goal.ID
+	}
+
+	unit := prefs.Weight
+	if unit == "" {
+		unit = units.Kilogram
+	}
+	forecast = forecast.InUnit(unit, func(kg float64) float64 { return units.FromKg(kg, unit) })
+
+	utils.SuccessResponse(c, http.StatusOK, "Weight forecast retrieved", forecast)
+}
+
+// forecastWeightGoal projects a weight goal with the configured window
+func forecastWeightGoal(goal models.Goal) models.WeightForecast {
+	window := config.AppConfig.WeightForecastWindowDays
+	now := time.Now()
+	return models.ForecastWeight(loadWeightSamples(goal.UserID, now.AddDate(0, 0, -window)), now, window, goal.Target, goal.Deadline)
+}
+
+// openWeightGoal returns the user's newest unfinished weight goal
+func openWeightGoal(userID uint) (models.Goal, bool) {
+	var goal models.Goal
+	result := database.DB.Where("user_id = ? AND type = ? AND is_completed = ? AND target > 0", userID, models.GoalTypeWeight, false).
+		Order("created_at desc").First(&goal)
+	return goal, result.Error == nil
+}
+
+// loadWeightSamples returns the user's weigh-ins since the given time,
+// oldest first
+func loadWeightSamples(userID uint, since time.Time) []models.WeightSample {
+	var records []models.HealthData
+	database.DB.Select("record_date", "weight_kg").
+		Where("user_id = ? AND record_date >= ? AND weight_kg > 0", userID, since).
+		Order("record_date asc, id asc").Find(&records)
+
+	samples := make([]models.WeightSample, len(records))
+	for i, r := range records {
+		samples[i] = models.WeightSample{Time: r.RecordDate, WeightKg: r.WeightKg}
+	}
+	return samples
+}
@@ -1,8 +1,10 @@
 package handlers
 
 import (
+	"errors"
 	"health-tracker/database"
 	"health-tracker/models"
+	"health-tracker/units"
 	"net/http"
 	"time"
 
@@ -27,6 +29,69 @@ func calculateDaysLeft(deadline string) int {
 	return days
 }
 
+// goalToMetric converts a goal value to kg or ml when its unit is a
+// weight or volume unit. Weight goals without a unit are read in the
+// user's preferred weight unit; other units are kept as given.
+func goalToMetric(goalType string, value float64, unit string, prefs units.Preferences) (float64, string, error) {
+	if unit == "" && goalType == models.GoalTypeWeight {
+		unit = prefs.Weight
+	}
+
+	switch {
+	case units.IsWeight(unit):
+		kg, err := units.ToKg(value, unit)
+		return kg, units.Kilogram, err
+	case units.IsVolume(unit):
+		ml, err := units.ToMl(value, unit)
+		return ml, units.Milliliter, err
+	case goalType == models.GoalTypeWeight:
+		return 0, "", errors.New("weight goals must use kg or lb")
+	}
+	return value, unit, nil
+}
+
+// goalFromMetric converts a stored goal value to the user's units
+func goalFromMetric(value float64, unit string, prefs units.Preferences) (float64, string) {
+	switch unit {
+	case units.Kilogram:
+		return units.Round(units.FromKg(value, prefs.Weight), 1), prefs.Weight
+	case units.Milliliter:
+		return units.Round(units.FromMl(value, prefs.Volume), 1), prefs.Volume
+	}
+	return value, unit
+}
+
+// newGoalResponse builds the response with weights and volumes in the
+// user's units. Weight goals also get a trend-based status.
+func newGoalResponse(goal models.Goal, prefs units.Preferences) models.GoalResponse {
+	target, unit := goalFromMetric(goal.Target, goal.Unit, prefs)
+	current, _ := goalFromMetric(goal.Current, goal.Unit, prefs)
+	resp := models.GoalResponse{
+		ID:          goal.ID,
+		Title:       goal.Title,
+		Description: goal.Description,
+		Type:        goal.Type,
+		Target:      target,
+		Current:     current,
+		Unit:        unit,
+		Deadline:    goal.Deadline,
+		IsCompleted: goal.IsCompleted,
+		Progress:    goal.GetProgress(),
+		DaysLeft:    calculateDaysLeft(goal.Deadline),
+	}
+
+	if goal.Type == models.GoalTypeWeight && goal.Target > 0 && (goal.Unit == units.Kilogram || goal.Unit == "") {
+		if goal.IsCompleted {
+			resp.Status = models.ForecastAchieved
+		} else {
+			forecast := forecastWeightGoal(goal)
+			resp.Status = forecast.Status
+			resp.ProjectedDate = forecast.ProjectedDate
+		}
+	}
+	return resp
+}
+
 // GetGoals returns all goals for the user
 func GetGoals(c *gin.Context) {
 	userID, exists := c.Get("userID")
@@ -41,21 +106,10 @@ func GetGoals(c *gin.Context) {
 		return
 	}
 
+	prefs := loadUnits(userID.(uint))
 	var response []models.GoalResponse
 	for _, goal := range goals {
-		response = append(response, models.GoalResponse{
-			ID:          goal.ID,
-			Title:       goal.Title,
-			Description: goal.Description,
-			Type:        goal.Type,
-			Target:      goal.Target,
-			Current:     goal.Current,
-			Unit:        goal.Unit,
-			Deadline:    goal.Deadline,
-			IsCompleted: goal.IsCompleted,
-			Progress:    goal.GetProgress(),
-			DaysLeft:    calculateDaysLeft(goal.Deadline),
-		})
+		response = append(response, newGoalResponse(goal, prefs))
 	}
 
 	c.JSON(http.StatusOK, response)
@@ -83,14 +137,20 @@ func CreateGoal(c *gin.Context) {
 		return
 	}
 
+	target, unit, err := goalToMetric(input.Type, input.Target, input.Unit, loadUnits(userID.(uint)))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	goal := models.Goal{
 		UserID:      userID.(uint),
 		Title:       input.Title,
 		Description: input.Description,
 		Type:        input.Type,
-		Target:      input.Target,
+		Target:      target,
 		Current:     0,
-		Unit:        input.Unit,
+		Unit:        unit,
 		Deadline:    input.Deadline,
 		IsCompleted: false,
 		CreatedAt:   time.Now(),
@@ -102,19 +162,7 @@ func CreateGoal(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, models.GoalResponse{
-		ID:          goal.ID,
-		Title:       goal.Title,
-		Description: goal.Description,
-		Type:        goal.Type,
-		Target:      goal.Target,
-		Current:     goal.Current,
-		Unit:        goal.Unit,
-		Deadline:    goal.Deadline,
-		IsCompleted: goal.IsCompleted,
-		Progress:    goal.GetProgress(),
-		DaysLeft:    calculateDaysLeft(goal.Deadline),
-	})
+	c.JSON(http.StatusCreated, newGoalResponse(goal, loadUnits(userID.(uint))))
 }
 
 // UpdateGoalProgress updates the current progress of a goal
@@ -132,8 +180,10 @@ func UpdateGoalProgress(c *gin.Context) {
 		return
 	}
 
+	// Current is in the unit the goal is shown in, unless unit is given
 	var input struct {
 		Current float64 `json:"current" binding:"required"`
+		Unit    string  `json:"unit"`
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -141,7 +191,18 @@ func UpdateGoalProgress(c *gin.Context) {
 		return
 	}
 
-	goal.Current = input.Current
+	prefs := loadUnits(userID.(uint))
+	unit := input.Unit
+	if unit == "" {
+		_, unit = goalFromMetric(0, goal.Unit, prefs)
+	}
+	current, currentUnit, err := goalToMetric(goal.Type, input.Current, unit, prefs)
+	if err != nil || currentUnit != goal.Unit {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Current must be in a unit compatible with " + goal.Unit})
+		return
+	}
+
+	goal.Current = current
 	goal.UpdatedAt = time.Now()
 
 	// Check if goal is completed
@@ -151,19 +212,7 @@ func UpdateGoalProgress(c *gin.Context) {
 
 	database.DB.Save(&goal)
 
-	c.JSON(http.StatusOK, models.GoalResponse{
-		ID:          goal.ID,
-		Title:       goal.Title,
-		Description: goal.Description,
-		Type:        goal.Type,
-		Target:      goal.Target,
-		Current:     goal.Current,
-		Unit:        goal.Unit,
-		Deadline:    goal.Deadline,
-		IsCompleted: goal.IsCompleted,
-		Progress:    goal.GetProgress(),
-		DaysLeft:    calculateDaysLeft(goal.Deadline),
-	})
+	c.JSON(http.StatusOK, newGoalResponse(goal, loadUnits(userID.(uint))))
 }
 
 // DeleteGoal deletes a goal
@@ -205,19 +254,7 @@ func ToggleGoalComplete(c *gin.Context) {
 	goal.UpdatedAt = time.Now()
 	database.DB.Save(&goal)
 
-	c.JSON(http.StatusOK, models.GoalResponse{
-		ID:          goal.ID,
-		Title:       goal.Title,
-		Description: goal.Description,
-		Type:        goal.Type,
-		Target:      goal.Target,
-		Current:     goal.Current,
-		Unit:        goal.Unit,
-		Deadline:    goal.Deadline,
-		IsCompleted: goal.IsCompleted,
-		Progress:    goal.GetProgress(),
-		DaysLeft:    calculateDaysLeft(goal.Deadline),
-	})
+	c.JSON(http.StatusOK, newGoalResponse(goal, loadUnits(userID.(uint))))
 }
 
 // GetGoalStats returns summary of goals
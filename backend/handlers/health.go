@@ -1,17 +1,21 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"time"
 
+	"health-tracker/config"
 	"health-tracker/database"
 	"health-tracker/models"
+	"health-tracker/units"
 	"health-tracker/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
-// CreateHealthData submits new health data
+// CreateHealthData submits new health data. RecordDate may be in the past
+// to enter earlier measurements.
 func CreateHealthData(c *gin.Context) {
 	userID := c.GetUint("userID")
 
@@ -21,19 +25,34 @@ func CreateHealthData(c *gin.Context) {
 		return
 	}
 
+	prefs := loadUnits(userID)
+	weightKg, heightCm, err := resolveMeasurements(req, prefs)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	recordDate := time.Now()
+	if req.RecordDate != "" {
+		if recordDate, err = parseRecordDate(req.RecordDate); err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid record_date: "+err.Error())
+			return
+		}
+	}
+
 	// Calculate BMI
-	bmi := models.CalculateBMI(req.WeightKg, req.HeightCm)
+	bmi := models.CalculateBMI(weightKg, heightCm)
 
 	healthData := models.HealthData{
 		UserID:         userID,
-		WeightKg:       req.WeightKg,
-		HeightCm:       req.HeightCm,
+		WeightKg:       weightKg,
+		HeightCm:       heightCm,
 		BMI:            bmi,
 		ActivityLevel:  req.ActivityLevel,
 		EmotionalState: req.EmotionalState,
 		DailySchedule:  req.DailySchedule,
 		Notes:          req.Notes,
-		RecordDate:     time.Now(),
+		RecordDate:     recordDate,
 	}
 
 	if result := database.DB.Create(&healthData); result.Error != nil {
@@ -42,16 +61,176 @@ func CreateHealthData(c *gin.Context) {
 	}
 
 	// Also update user's base info
-	database.DB.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
-		"weight_kg":      req.WeightKg,
-		"height_cm":      req.HeightCm,
-		"activity_level": req.ActivityLevel,
-	})
+	syncUserMeasurements(userID)
 
+	bmiResult := classifyBMI(userID, healthData)
 	utils.SuccessResponse(c, http.StatusCreated, "Health data saved", gin.H{
-		"health_data":  healthData,
-		"bmi_category": models.GetBMICategory(bmi),
+		"health_data":        models.NewHealthDataView(healthData, prefs),
+		"bmi_category":       bmiResult.Category,
+		"bmi_classification": bmiResult,
+	})
+}
+
+// UpdateHealthData edits a health record and recomputes its BMI
+func UpdateHealthData(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var healthData models.HealthData
+	if result := database.DB.Where("id = ? AND user_id = ?", c.Param("id"), userID).First(&healthData); result.Error != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Health record not found")
+		return
+	}
+
+	var req models.HealthDataRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	prefs := loadUnits(userID)
+	weightKg, heightCm, err := resolveMeasurements(req, prefs)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	if req.RecordDate != "" {
+		recordDate, err := parseRecordDate(req.RecordDate)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid record_date: "+err.Error())
+			return
+		}
+		healthData.RecordDate = recordDate
+	}
+
+	healthData.WeightKg = weightKg
+	healthData.HeightCm = heightCm
+	healthData.BMI = models.CalculateBMI(weightKg, heightCm)
+	healthData.ActivityLevel = req.ActivityLevel
+	healthData.EmotionalState = req.EmotionalState
+	healthData.DailySchedule = req.DailySchedule
+	healthData.Notes = req.Notes
+
+	if result := database.DB.Save(&healthData); result.Error != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update health data")
+		return
+	}
+
+	syncUserMeasurements(userID)
+
+	bmiResult := classifyBMI(userID, healthData)
+	utils.SuccessResponse(c, http.StatusOK, "Health data updated", gin.H{
+		"health_data":        models.NewHealthDataView(healthData, prefs),
+		"bmi_category":       bmiResult.Category,
+		"bmi_classification": bmiResult,
+	})
+}
+
+// DeleteHealthData removes a health record
+func DeleteHealthData(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	result := database.DB.Where("id = ? AND user_id = ?", c.Param("id"), userID).Delete(&models.HealthData{})
+	if result.Error != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to delete health data")
+		return
+	}
+	if result.RowsAffected == 0 {
+		utils.ErrorResponse(c, http.StatusNotFound, "Health record not found")
+		return
+	}
+
+	syncUserMeasurements(userID)
+
+	utils.SuccessResponse(c, http.StatusOK, "Health data deleted", nil)
+}
+
+// syncUserMeasurements copies the most recent record by date onto the
+// user's profile, so backdated entries do not overwrite newer values.
+// The profile is left alone when no records remain.
+func syncUserMeasurements(userID uint) {
+	var latest models.HealthData
+	if result := database.DB.Where("user_id = ?", userID).Order("record_date desc, id desc").First(&latest); result.Error != nil {
+		return
+	}
+
+	database.DB.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"weight_kg": latest.WeightKg,
+		"height_cm": latest.HeightCm,
 	})
+	if latest.ActivityLevel != "" {
+		database.DB.Model(&models.User{}).
+			Where("id = ? AND activity_level_source <> ?", userID, models.ActivitySourceWorkouts).
+			Update("activity_level", latest.ActivityLevel)
+	}
+}
+
+// resolveMeasurements returns the request's weight in kg and height in
+// cm. weight and height take precedence over weight_kg and height_cm and
+// are read in their given unit, or the user's preferred one.
+func resolveMeasurements(req models.HealthDataRequest, prefs units.Preferences) (float64, float64, error) {
+	weightKg, heightCm := req.WeightKg, req.HeightCm
+
+	if req.Weight != nil {
+		unit := req.WeightUnit
+		if unit == "" {
+			unit = prefs.Weight
+		}
+		var err error
+		if weightKg, err = units.ToKg(*req.Weight, unit); err != nil {
+			return 0, 0, err
+		}
+	}
+	if req.Height != nil {
+		unit := req.HeightUnit
+		if unit == "" {
+			unit = prefs.Height
+		}
+		var err error
+		if heightCm, err = units.ToCm(*req.Height, unit); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	if weightKg <= 0 {
+		return 0, 0, errors.New("weight or weight_kg is required")
+	}
+	if heightCm <= 0 {
+		return 0, 0, errors.New("height or height_cm is required")
+	}
+	return weightKg, heightCm, nil
+}
+
+// classifyBMI interprets a health record's BMI for the owner's age and
+// sex on the day it was recorded, using the configured adult scheme
+func classifyBMI(userID uint, health models.HealthData) models.BMIClassification {
+	var user models.User
+	database.DB.Select("id", "sex", "birth_date").First(&user, userID)
+	return models.ClassifyBMI(health.BMI, user.Sex, user.BirthDate, health.RecordDate, config.AppConfig.BMIAdultScheme)
+}
+
+// parseRecordDate accepts YYYY-MM-DD or RFC 3339. A bare date for today
+// means now; an earlier bare date is placed at noon so it sorts within its day.
+func parseRecordDate(value string) (time.Time, error) {
+	now := time.Now()
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		day, dateErr := time.ParseInLocation("2006-01-02", value, time.Local)
+		if dateErr != nil {
+			return time.Time{}, errors.New("use YYYY-MM-DD or RFC 3339")
+		}
+		if day.Format("2006-01-02") == now.Format("2006-01-02") {
+			return now, nil
+		}
+		t = day.Add(12 * time.Hour)
+	}
+
+	// Allow a little clock skew between client and server
+	if t.After(now.Add(5 * time.Minute)) {
+		return time.Time{}, errors.New("must not be in the future")
+	}
+	return t, nil
 }
 
 // GetHealthData returns all health records for current user
@@ -61,7 +240,13 @@ func GetHealthData(c *gin.Context) {
 	var healthData []models.HealthData
 	database.DB.Where("user_id = ?", userID).Order("record_date desc").Find(&healthData)
 
-	utils.SuccessResponse(c, http.StatusOK, "Health data retrieved", healthData)
+	prefs := loadUnits(userID)
+	views := make([]models.HealthDataView, len(healthData))
+	for i, hd := range healthData {
+		views[i] = models.NewHealthDataView(hd, prefs)
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Health data retrieved", views)
 }
 
 // GetLatestHealthData returns the latest health record
@@ -76,9 +261,11 @@ func GetLatestHealthData(c *gin.Context) {
 		return
 	}
 
+	bmiResult := classifyBMI(userID, healthData)
 	utils.SuccessResponse(c, http.StatusOK, "Latest health data", gin.H{
-		"health_data":  healthData,
-		"bmi_category": models.GetBMICategory(healthData.BMI),
+		"health_data":        models.NewHealthDataView(healthData, loadUnits(userID)),
+		"bmi_category":       bmiResult.Category,
+		"bmi_classification": bmiResult,
 	})
 }
 
@@ -103,20 +290,33 @@ func GetDashboard(c *gin.Context) {
 	var weeklyProgress []models.HealthData
 	database.DB.Where("user_id = ?", userID).Order("record_date desc").Limit(7).Find(&weeklyProgress)
 
-	// Calculate health score (simplified)
-	healthScore := calculateHealthScore(latestHealth, recentSymptoms)
+	bmiResult := classifyBMI(userID, latestHealth)
+
+	// Health score with its breakdown and recent history
+	healthScore, scoreHistory := loadHealthScores(userID, dashboardScoreDays)
 
 	// Get recommendations
-	recommendations := getQuickRecommendations(latestHealth, recentSymptoms)
+	recommendations := getQuickRecommendations(latestHealth, bmiResult.Category, recentSymptoms)
+
+	prefs := loadUnits(userID)
+	latestView := models.NewHealthDataView(latestHealth, prefs)
+	progressViews := make([]models.HealthDataView, len(weeklyProgress))
+	for i, hd := range weeklyProgress {
+		progressViews[i] = models.NewHealthDataView(hd, prefs)
+	}
 
 	dashboard := models.DashboardData{
-		LatestHealth:    &latestHealth,
-		BMICategory:     models.GetBMICategory(latestHealth.BMI),
-		HealthScore:     healthScore,
+		LatestHealth:    &latestView,
+		BMICategory:     bmiResult.Category,
+		BMI:             bmiResult,
+		HealthScore:     healthScore.Score,
+		ScoreBreakdown:  healthScore.Components,
+		ScoreHistory:    scoreHistory,
 		TotalRecords:    totalRecords,
 		RecentSymptoms:  recentSymptoms,
-		WeeklyProgress:  weeklyProgress,
+		WeeklyProgress:  progressViews,
 		Recommendations: recommendations,
+		Alerts:          loadOpenAlerts(userID, prefs),
 	}
 
 	utils.SuccessResponse(c, http.StatusOK, "Dashboard data retrieved", dashboard)
@@ -125,32 +325,20 @@ func GetDashboard(c *gin.Context) {
 // GetHealthGraph returns graph data for specified period
 func GetHealthGraph(c *gin.Context) {
 	userID := c.GetUint("userID")
-	period := c.Param("period") // week, month, year
-
-	var days int
-	switch period {
-	case "week":
-		days = 7
-	case "month":
-		days = 30
-	case "year":
-		days = 365
-	default:
-		days = 7
-	}
-
-	startDate := time.Now().AddDate(0, 0, -days)
+	startDate := graphPeriodStart(c.Param("period"))
 
 	var healthData []models.HealthData
 	database.DB.Where("user_id = ? AND record_date >= ?", userID, startDate).
 		Order("record_date asc").Find(&healthData)
 
-	// Prepare graph data
+	// Prepare graph data, weight in the user's unit
+	weightUnit := loadUnits(userID).Weight
 	graphData := make([]map[string]interface{}, len(healthData))
 	for i, hd := range healthData {
 		graphData[i] = map[string]interface{}{
 			"date":            hd.RecordDate.Format("2006-01-02"),
-			"weight":          hd.WeightKg,
+			"weight":          units.Round(units.FromKg(hd.WeightKg, weightUnit), 1),
+			"weight_unit":     weightUnit,
 			"bmi":             hd.BMI,
 			"emotional_state": hd.EmotionalState,
 		}
@@ -159,44 +347,28 @@ func GetHealthGraph(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, "Graph data retrieved", graphData)
 }
 
-func calculateHealthScore(health models.HealthData, symptoms []models.Symptom) int {
-	score := 100
-
-	// Deduct points based on BMI
-	bmiCategory := models.GetBMICategory(health.BMI)
-	switch bmiCategory {
-	case "Underweight":
-		score -= 15
-	case "Overweight":
-		score -= 10
-	case "Obese":
-		score -= 25
-	}
-
-	// Deduct points for symptoms
-	score -= len(symptoms) * 5
-
-	// Deduct based on emotional state
-	switch health.EmotionalState {
-	case "stressed", "anxious":
-		score -= 10
-	case "sad":
-		score -= 15
-	}
-
-	if score < 0 {
-		score = 0
+// graphPeriodStart maps a graph period (week, month, year) to its start time
+func graphPeriodStart(period string) time.Time {
+	var days int
+	switch period {
+	case "week":
+		days = 7
+	case "month":
+		days = 30
+	case "year":
+		days = 365
+	default:
+		days = 7
 	}
 
-	return score
+	return time.Now().AddDate(0, 0, -days)
 }
 
-func getQuickRecommendations(health models.HealthData, symptoms []models.Symptom) []models.RecommendationItem {
+func getQuickRecommendations(health models.HealthData, bmiCategory string, symptoms []models.Symptom) []models.RecommendationItem {
 	var recommendations []models.RecommendationItem
 
 	// BMI-based recommendation
-	bmiCategory := models.GetBMICategory(health.BMI)
-	if bmiCategory != "Normal" {
+	if bmiCategory != models.BMINormal && bmiCategory != "" {
 		recommendations = append(recommendations, models.RecommendationItem{
 			Type:        "health",
 			Title:       "Perhatikan BMI Anda",
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"health-tracker/audit"
+	"health-tracker/config"
+	"health-tracker/database"
+	"health-tracker/models"
+	"health-tracker/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// recordLoginAttempt stores a sign-in attempt. user may be nil for unknown emails.
+func recordLoginAttempt(c *gin.Context, user *models.User, email string, success bool, reason string) {
+	attempt := models.LoginAttempt{
+		Email:         email,
+		IPAddress:     c.ClientIP(),
+		UserAgent:     truncate(c.Request.UserAgent(), 255),
+		Success:       success,
+		FailureReason: reason,
+	}
+	if user != nil {
+		attempt.UserID = &user.ID
+	}
+	database.DB.Create(&attempt)
+
+	entry := audit.Entry{Action: models.AuditLoginSucceeded, Details: audit.Details{"email": email}}
+	if !success {
+		entry.Action = models.AuditLoginFailed
+		entry.Details["reason"] = reason
+	}
+	if user != nil {
+		entry.ActorID = user.ID
+		entry.TargetUserID = user.ID
+	}
+	audit.Record(c, entry)
+}
+
+// accountLockedFor returns how long the account stays locked, or zero
+func accountLockedFor(user *models.User) time.Duration {
+	if user.LockedUntil == nil {
+		return 0
+	}
+	remaining := time.Until(*user.LockedUntil)
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// rejectLockedAccount responds with 429 if the account is locked
+func rejectLockedAccount(c *gin.Context, user *models.User) bool {
+	remaining := accountLockedFor(user)
+	if remaining == 0 {
+		return false
+	}
+
+	recordLoginAttempt(c, user, user.Email, false, models.LoginFailureAccountLocked)
+	respondAccountLocked(c, remaining)
+	return true
+}
+
+// rejectUnknownEmail answers a login for an email without an account. The
+// email is locked out after the same number of failures as a real account,
+// so a 429 does not reveal that an email is registered.
+func rejectUnknownEmail(c *gin.Context, email string) {
+	var failures int64
+	database.DB.Model(&models.LoginAttempt{}).
+		Where("email = ? AND user_id IS NULL AND failure_reason = ?", email, models.LoginFailureUnknownEmail).
+		Count(&failures)
+
+	if lock := lockoutDuration(int(failures)); lock > 0 {
+		var last models.LoginAttempt
+		database.DB.Where("email = ? AND user_id IS NULL AND failure_reason = ?", email, models.LoginFailureUnknownEmail).
+			Order("created_at desc").First(&last)
+		if remaining := time.Until(last.CreatedAt.Add(lock)); remaining > 0 {
+			recordLoginAttempt(c, nil, email, false, models.LoginFailureAccountLocked)
+			respondAccountLocked(c, remaining)
+			return
+		}
+	}
+
+	recordLoginAttempt(c, nil, email, false, models.LoginFailureUnknownEmail)
+	utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid email or password")
+}
+
+func respondAccountLocked(c *gin.Context, remaining time.Duration) {
+	minutes := int(math.Ceil(remaining.Minutes()))
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(remaining.Seconds()))))
+	utils.ErrorResponse(c, http.StatusTooManyRequests,
+		fmt.Sprintf("Akun dikunci sementara karena terlalu banyak percobaan login gagal. Coba lagi dalam %d menit.", minutes))
+}
+
+// registerLoginFailure counts a failed attempt and locks the account with
+// exponential backoff once the threshold is reached
+func registerLoginFailure(c *gin.Context, user *models.User, reason string) {
+	recordLoginAttempt(c, user, user.Email, false, reason)
+
+	database.DB.Model(&models.User{}).Where("id = ?", user.ID).
+		Update("failed_login_attempts", gorm.Expr("failed_login_attempts + 1"))
+
+	var failures int
+	database.DB.Model(&models.User{}).Where("id = ?", user.ID).
+		Select("failed_login_attempts").Scan(&failures)
+
+	if lock := lockoutDuration(failures); lock > 0 {
+		database.DB.Model(&models.User{}).Where("id = ?", user.ID).
+			Update("locked_until", time.Now().Add(lock))
+	}
+}
+
+// lockoutDuration returns the lock length after the given number of
+// consecutive failures: base, 2x base, 4x base, ... up to the maximum
+func lockoutDuration(failures int) time.Duration {
+	cfg := config.AppConfig
+	if cfg.LoginLockoutThreshold <= 0 || failures < cfg.LoginLockoutThreshold {
+		return 0
+	}
+
+	base := time.Duration(cfg.LoginLockoutBaseMinutes) * time.Minute
+	max := time.Duration(cfg.LoginLockoutMaxMinutes) * time.Minute
+
+	exponent := failures - cfg.LoginLockoutThreshold
+	if exponent > 20 {
+		return max
+	}
+	lock := base << uint(exponent)
+	if lock > max {
+		return max
+	}
+	return lock
+}
+
+// registerLoginSuccess clears the failure counter and records the attempt
+func registerLoginSuccess(c *gin.Context, user *models.User) {
+	recordLoginAttempt(c, user, user.Email, true, "")
+
+	if user.FailedLoginAttempts > 0 || user.LockedUntil != nil {
+		database.DB.Model(&models.User{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
+			"failed_login_attempts": 0,
+			"locked_until":          nil,
+		})
+	}
+}
+
+// GetLoginHistory returns the current user's recent sign-in attempts
+func GetLoginHistory(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	var attempts []models.LoginAttempt
+	database.DB.Where("user_id = ?", userID).Order("created_at desc").Limit(limit).Find(&attempts)
+
+	utils.SuccessResponse(c, http.StatusOK, "Login history retrieved", attempts)
+}
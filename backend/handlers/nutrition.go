@@ -0,0 +1,290 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"health-tracker/database"
+	"health-tracker/models"
+	"health-tracker/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SearchFoods searches the nutrition database by name. Filters: q,
+// category, with page and limit.
+func SearchFoods(c *gin.Context) {
+	query := database.DB.Model(&models.Food{})
+
+	if q := strings.TrimSpace(c.Query("q")); q != "" {
+		pattern := "%" + strings.ToLower(strings.ReplaceAll(q, "%", "")) + "%"
+		query = query.Where("LOWER(name) LIKE ?", pattern)
+	}
+	if category := c.Query("category"); category != "" {
+		query = query.Where("category = ?", category)
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var foods []models.Food
+	query.Order("name asc").Offset((page - 1) * limit).Limit(limit).Find(&foods)
+
+	utils.SuccessResponse(c, http.StatusOK, "Foods retrieved", gin.H{
+		"foods": foods,
+		"total": total,
+		"page":  page,
+		"limit": limit,
+	})
+}
+
+// GetFood returns a food with the nutrients of one serving
+func GetFood(c *gin.Context) {
+	var food models.Food
+	if result := database.DB.First(&food, c.Param("id")); result.Error != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Food not found")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Food retrieved", gin.H{
+		"food":    food,
+		"serving": roundNutrients(food.Portion(food.ServingGrams)),
+	})
+}
+
+// LogMeal records a portion of a food the user ate
+func LogMeal(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var req models.MealLogRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	meal := models.MealLog{UserID: userID, EatenAt: time.Now()}
+	if !applyMealRequest(c, &meal, req) {
+		return
+	}
+
+	if result := database.DB.Create(&meal); result.Error != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to save meal")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Meal logged", meal)
+}
+
+// GetMeals lists logged meals, newest first. Filters: from and to
+// (YYYY-MM-DD), meal_type, with page and limit.
+func GetMeals(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	query := database.DB.Model(&models.MealLog{}).Where("user_id = ?", userID)
+
+	if mealType := c.Query("meal_type"); mealType != "" {
+		query = query.Where("meal_type = ?", mealType)
+	}
+	if from := c.Query("from"); from != "" {
+		t, err := time.ParseInLocation("2006-01-02", from, time.Local)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid from date, use YYYY-MM-DD")
+			return
+		}
+		query = query.Where("eaten_at >= ?", t)
+	}
+	if to := c.Query("to"); to != "" {
+		t, err := time.ParseInLocation("2006-01-02", to, time.Local)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid to date, use YYYY-MM-DD")
+			return
+		}
+		query = query.Where("eaten_at < ?", t.AddDate(0, 0, 1))
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 200 {
+		limit = 50
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var meals []models.MealLog
+	query.Order("eaten_at desc, id desc").Offset((page - 1) * limit).Limit(limit).Find(&meals)
+
+	utils.SuccessResponse(c, http.StatusOK, "Meals retrieved", gin.H{
+		"meals": meals,
+		"total": total,
+		"page":  page,
+		"limit": limit,
+	})
+}
+
+// UpdateMeal edits a logged meal and recalculates its nutrients
+func UpdateMeal(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var meal models.MealLog
+	if result := database.DB.Where("id = ? AND user_id = ?", c.Param("id"), userID).First(&meal); result.Error != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Meal not found")
+		return
+	}
+
+	var req models.MealLogRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	if !applyMealRequest(c, &meal, req) {
+		return
+	}
+
+	if result := database.DB.Save(&meal); result.Error != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update meal")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Meal updated", meal)
+}
+
+// DeleteMeal removes a logged meal
+func DeleteMeal(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	result := database.DB.Where("id = ? AND user_id = ?", c.Param("id"), userID).Delete(&models.MealLog{})
+	if result.Error != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to delete meal")
+		return
+	}
+	if result.RowsAffected == 0 {
+		utils.ErrorResponse(c, http.StatusNotFound, "Meal not found")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Meal deleted", nil)
+}
+
+// GetDailyNutrition totals a day's meals (date=YYYY-MM-DD, default today)
+// and compares them with the user's targets
+func GetDailyNutrition(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	day := time.Now()
+	if date := c.Query("date"); date != "" {
+		t, err := time.ParseInLocation("2006-01-02", date, time.Local)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid date, use YYYY-MM-DD")
+			return
+		}
+		day = t
+	}
+	start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.Local)
+
+	var meals []models.MealLog
+	database.DB.Where("user_id = ? AND eaten_at >= ? AND eaten_at < ?", userID, start, start.AddDate(0, 0, 1)).
+		Order("eaten_at asc, id asc").Find(&meals)
+
+	var user models.User
+	database.DB.First(&user, userID)
+	refreshActivityLevel(&user)
+
+	daily := models.DailyNutrition{
+		Date:   start.Format("2006-01-02"),
+		Target: computeEnergyTargets(user).Target,
+		ByMeal: make(map[string]models.Nutrients),
+		Meals:  meals,
+	}
+	for _, mealType := range models.MealTypes {
+		daily.ByMeal[mealType] = models.Nutrients{}
+	}
+	for _, m := range meals {
+		daily.Totals = daily.Totals.Add(m.Nutrients())
+		daily.ByMeal[m.MealType] = daily.ByMeal[m.MealType].Add(m.Nutrients())
+	}
+	for mealType, n := range daily.ByMeal {
+		daily.ByMeal[mealType] = roundNutrients(n)
+	}
+
+	daily.Totals = roundNutrients(daily.Totals)
+	daily.Remaining = roundNutrients(daily.Target.Sub(daily.Totals))
+	if daily.Target.Calories > 0 {
+		daily.CaloriePercentage = round1(daily.Totals.Calories / daily.Target.Calories * 100)
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Daily nutrition retrieved", daily)
+}
+
+// applyMealRequest resolves the food and portion and copies the nutrients
+// onto meal. It writes the error response and returns false when the
+// request is invalid.
+func applyMealRequest(c *gin.Context, meal *models.MealLog, req models.MealLogRequest) bool {
+	var food models.Food
+	if result := database.DB.First(&food, req.FoodID); result.Error != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Unknown food_id")
+		return false
+	}
+	if req.Grams != nil && req.Servings != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Give either grams or servings, not both")
+		return false
+	}
+
+	if req.EatenAt != "" {
+		eatenAt, err := parseRecordDate(req.EatenAt)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid eaten_at: "+err.Error())
+			return false
+		}
+		meal.EatenAt = eatenAt
+	}
+
+	grams := food.ServingGrams
+	switch {
+	case req.Grams != nil:
+		grams = *req.Grams
+	case req.Servings != nil:
+		grams = *req.Servings * food.ServingGrams
+	}
+
+	n := roundNutrients(food.Portion(grams))
+	meal.FoodID = food.ID
+	meal.FoodName = food.Name
+	meal.MealType = req.MealType
+	meal.Grams = round1(grams)
+	meal.Calories = n.Calories
+	meal.ProteinG = n.ProteinG
+	meal.CarbsG = n.CarbsG
+	meal.FatG = n.FatG
+	meal.FiberG = n.FiberG
+	meal.SodiumMg = n.SodiumMg
+	meal.Notes = req.Notes
+	return true
+}
+
+func roundNutrients(n models.Nutrients) models.Nutrients {
+	return models.Nutrients{
+		Calories: math.Round(n.Calories),
+		ProteinG: round1(n.ProteinG),
+		CarbsG:   round1(n.CarbsG),
+		FatG:     round1(n.FatG),
+		FiberG:   round1(n.FiberG),
+		SodiumMg: math.Round(n.SodiumMg),
+	}
+}
@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"health-tracker/config"
+	"health-tracker/database"
+	"health-tracker/models"
+	"health-tracker/oidc"
+	"health-tracker/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var (
+	errUnverifiedProviderEmail = errors.New("provider did not return a verified email")
+	errUnverifiedLocalAccount  = errors.New("local account with this email is not verified")
+)
+
+// GetOIDCProviders lists the configured social login providers
+func GetOIDCProviders(c *gin.Context) {
+	utils.SuccessResponse(c, http.StatusOK, "Login providers retrieved", oidc.ProviderNames())
+}
+
+// OIDCAuthorize starts an authorization code flow with PKCE. The frontend
+// redirects the browser to the returned URL.
+func OIDCAuthorize(c *gin.Context) {
+	provider, ok := oidc.GetProvider(c.Param("provider"))
+	if !ok {
+		utils.ErrorResponse(c, http.StatusNotFound, "Unknown login provider")
+		return
+	}
+
+	state, err := oidc.NewState()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to start login")
+		return
+	}
+	nonce, err := oidc.NewState()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to start login")
+		return
+	}
+	verifier, err := oidc.NewCodeVerifier()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to start login")
+		return
+	}
+
+	authURL, err := provider.AuthCodeURL(c.Request.Context(), state, nonce, verifier)
+	if err != nil {
+		log.Printf("OIDC discovery failed for %s: %v", provider.Name, err)
+		utils.ErrorResponse(c, http.StatusBadGateway, "Login provider is unavailable")
+		return
+	}
+
+	expiry := time.Duration(config.AppConfig.OIDCStateExpiryMinutes) * time.Minute
+
+	// Drop abandoned requests so the table does not grow forever
+	database.DB.Where("expires_at < ?", time.Now()).Delete(&models.OIDCAuthRequest{})
+
+	request := models.OIDCAuthRequest{
+		StateHash:    utils.HashToken(state),
+		Provider:     provider.Name,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    time.Now().Add(expiry),
+	}
+	if err := database.DB.Create(&request).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to start login")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Authorization URL created", models.OIDCAuthorizeResponse{
+		AuthorizationURL: authURL,
+		State:            state,
+		ExpiresIn:        int(expiry.Seconds()),
+	})
+}
+
+// OIDCCallback completes the flow with the code and state the provider
+// sent back to the frontend, then signs the user in
+func OIDCCallback(c *gin.Context) {
+	provider, ok := oidc.GetProvider(c.Param("provider"))
+	if !ok {
+		utils.ErrorResponse(c, http.StatusNotFound, "Unknown login provider")
+		return
+	}
+
+	var req models.OIDCCallbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	// The state is single use: it is deleted before the code is exchanged
+	var request models.OIDCAuthRequest
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("state_hash = ? AND provider = ? AND expires_at > ?",
+			utils.HashToken(req.State), provider.Name, time.Now()).First(&request).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&request)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errTokenAlreadyUsed
+		}
+		return nil
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid or expired login state, please try again")
+		return
+	}
+
+	claims, err := provider.Exchange(c.Request.Context(), req.Code, request.CodeVerifier, request.Nonce)
+	if err != nil {
+		log.Printf("OIDC code exchange failed for %s: %v", provider.Name, err)
+		recordLoginAttempt(c, nil, "", false, models.LoginFailureOIDC)
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Login with provider failed")
+		return
+	}
+
+	user, err := findOrLinkOIDCUser(provider.Name, claims)
+	if errors.Is(err, errUnverifiedProviderEmail) {
+		recordLoginAttempt(c, nil, claims.Email, false, models.LoginFailureOIDC)
+		utils.ErrorResponse(c, http.StatusForbidden, "Your provider account has no verified email address")
+		return
+	}
+	if errors.Is(err, errUnverifiedLocalAccount) {
+		recordLoginAttempt(c, nil, claims.Email, false, models.LoginFailureOIDC)
+		utils.ErrorResponse(c, http.StatusConflict,
+			"An account with this email already exists. Sign in with your password and verify your email before using this provider")
+		return
+	}
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to sign in")
+		return
+	}
+
+	if rejectLockedAccount(c, &user) {
+		return
+	}
+
+	// The provider replaces the password, not the second factor
+	if user.TwoFactorEnabled {
+		challengeToken, err := utils.GenerateTwoFactorToken(user.ID, user.Email)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to generate token")
+			return
+		}
+
+		utils.SuccessResponse(c, http.StatusOK, "Two-factor authentication required", models.TwoFactorChallengeResponse{
+			TwoFactorRequired: true,
+			TwoFactorToken:    challengeToken,
+			ExpiresIn:         config.AppConfig.TwoFactorTokenExpiryMinutes * 60,
+		})
+		return
+	}
+
+	response, err := startSession(c, user)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to generate token")
+		return
+	}
+
+	registerLoginSuccess(c, &user)
+
+	utils.SuccessResponse(c, http.StatusOK, "Login successful", response)
+}
+
+// findOrLinkOIDCUser resolves the provider identity to a local user.
+// Known identities sign in directly. Otherwise the identity is linked to
+// the account with the same email if that account has verified it, or a
+// new account is created.
+func findOrLinkOIDCUser(providerName string, claims *oidc.IDTokenClaims) (models.User, error) {
+	var user models.User
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var identity models.UserIdentity
+		err := tx.Where("provider = ? AND subject = ?", providerName, claims.Subject).First(&identity).Error
+		if err == nil {
+			return tx.First(&user, identity.UserID).Error
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		// Linking by an unverified email would let anyone take over an account
+		email := strings.TrimSpace(claims.Email)
+		if email == "" || !bool(claims.EmailVerified) {
+			return errUnverifiedProviderEmail
+		}
+
+		err = tx.Where("email = ?", email).First(&user).Error
+		switch {
+		case err == nil:
+			// Whoever registered an unverified account may not own the
+			// address, and their password would keep working after the link
+			if !user.EmailVerified {
+				return errUnverifiedLocalAccount
+			}
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			if user, err = createOIDCUser(tx, email, claims.Name); err != nil {
+				return err
+			}
+		default:
+			return err
+		}
+
+		return tx.Create(&models.UserIdentity{
+			UserID:   user.ID,
+			Provider: providerName,
+			Subject:  claims.Subject,
+			Email:    email,
+		}).Error
+	})
+
+	return user, err
+}
+
+// createOIDCUser registers a user whose only credential is the provider.
+// The random password can only be replaced through the reset flow.
+func createOIDCUser(tx *gorm.DB, email, name string) (models.User, error) {
+	password, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return models.User{}, err
+	}
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	if name == "" {
+		name = strings.Split(email, "@")[0]
+	}
+
+	user := models.User{
+		Email:         email,
+		Password:      hashedPassword,
+		Name:          name,
+		Role:          models.RoleUser,
+		EmailVerified: true,
+	}
+	err = tx.Create(&user).Error
+	return user, err
+}
+
+// GetLinkedIdentities lists the social login accounts linked to the user
+func GetLinkedIdentities(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var identities []models.UserIdentity
+	database.DB.Where("user_id = ?", userID).Order("created_at").Find(&identities)
+
+	utils.SuccessResponse(c, http.StatusOK, "Linked identities retrieved", identities)
+}
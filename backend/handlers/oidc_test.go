@@ -0,0 +1,390 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"health-tracker/config"
+	"health-tracker/database"
+	"health-tracker/models"
+	"health-tracker/oidc"
+	"health-tracker/oidc/oidctest"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const testClientID = "health-tracker"
+
+var mockUser = oidctest.User{
+	Subject:       "mock-user-1",
+	Email:         "mock.user@example.com",
+	EmailVerified: true,
+	Name:          "Mock User",
+}
+
+// setupOIDC points the handlers at a fresh database and a mock provider
+// registered as "mock"
+func setupOIDC(t *testing.T, user oidctest.User) (*oidctest.Server, *gin.Engine) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	config.AppConfig = &config.Config{
+		JWTSecret:                   "test-secret",
+		JWTAlgorithm:                "HS256",
+		JWTExpiryHours:              1,
+		RefreshTokenExpiryDays:      1,
+		TwoFactorTokenExpiryMinutes: 5,
+		LoginLockoutThreshold:       5,
+		LoginLockoutBaseMinutes:     1,
+		LoginLockoutMaxMinutes:      60,
+		OIDCStateExpiryMinutes:      10,
+	}
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	if err := db.AutoMigrate(
+		&models.User{},
+		&models.UserIdentity{},
+		&models.OIDCAuthRequest{},
+		&models.LoginAttempt{},
+		&models.AuditLog{},
+		&models.Session{},
+		&models.RefreshToken{},
+	); err != nil {
+		t.Fatalf("migrate database: %v", err)
+	}
+	database.DB = db
+
+	server, ts, err := oidctest.StartTestServer(testClientID, user)
+	if err != nil {
+		t.Fatalf("start mock provider: %v", err)
+	}
+	t.Cleanup(ts.Close)
+
+	oidc.InitProviders([]oidc.Config{{
+		Name:        "mock",
+		Issuer:      server.Issuer,
+		ClientID:    testClientID,
+		RedirectURL: "http://localhost:5173/auth/callback/mock",
+	}})
+
+	router := gin.New()
+	router.GET("/api/auth/oidc/:provider/authorize", OIDCAuthorize)
+	router.POST("/api/auth/oidc/:provider/callback", OIDCCallback)
+	return server, router
+}
+
+type apiResponse struct {
+	Success bool            `json:"success"`
+	Data    json.RawMessage `json:"data"`
+	Error   string          `json:"error"`
+}
+
+func doJSON(t *testing.T, router *gin.Engine, method, path string, body interface{}) (int, apiResponse) {
+	t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		json.NewEncoder(&buf).Encode(body)
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	var resp apiResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("%s %s: decode response %q: %v", method, path, rec.Body.String(), err)
+	}
+	return rec.Code, resp
+}
+
+// authorize starts a login and lets the mock provider approve it,
+// returning the code and state it redirects back with
+func authorize(t *testing.T, router *gin.Engine) (code, state string) {
+	t.Helper()
+	status, resp := doJSON(t, router, http.MethodGet, "/api/auth/oidc/mock/authorize", nil)
+	if status != http.StatusOK {
+		t.Fatalf("authorize: status %d: %s", status, resp.Error)
+	}
+	var started models.OIDCAuthorizeResponse
+	if err := json.Unmarshal(resp.Data, &started); err != nil {
+		t.Fatalf("authorize: decode data: %v", err)
+	}
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	res, err := client.Get(started.AuthorizationURL)
+	if err != nil {
+		t.Fatalf("provider authorize: %v", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusFound {
+		t.Fatalf("provider authorize: status %d", res.StatusCode)
+	}
+	location, err := url.Parse(res.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("provider redirect: %v", err)
+	}
+	if got := location.Query().Get("state"); got != started.State {
+		t.Fatalf("provider returned state %q, want %q", got, started.State)
+	}
+	return location.Query().Get("code"), location.Query().Get("state")
+}
+
+func callback(t *testing.T, router *gin.Engine, code, state string) (int, apiResponse) {
+	t.Helper()
+	return doJSON(t, router, http.MethodPost, "/api/auth/oidc/mock/callback",
+		models.OIDCCallbackRequest{Code: code, State: state})
+}
+
+func signIn(t *testing.T, router *gin.Engine) (int, apiResponse) {
+	t.Helper()
+	code, state := authorize(t, router)
+	return callback(t, router, code, state)
+}
+
+func loginUser(t *testing.T, resp apiResponse) models.User {
+	t.Helper()
+	var login models.LoginResponse
+	if err := json.Unmarshal(resp.Data, &login); err != nil {
+		t.Fatalf("decode login response: %v", err)
+	}
+	if login.Token == "" || login.RefreshToken == "" {
+		t.Fatalf("login response has no tokens: %s", resp.Data)
+	}
+	return login.User
+}
+
+func createLocalUser(t *testing.T, email string, verified bool) models.User {
+	t.Helper()
+	user := models.User{
+		Email:         email,
+		Password:      "local-password-hash",
+		Name:          "Local User",
+		Role:          models.RoleUser,
+		EmailVerified: verified,
+	}
+	if err := database.DB.Create(&user).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	return user
+}
+
+func countIdentities(t *testing.T) int64 {
+	t.Helper()
+	var n int64
+	database.DB.Model(&models.UserIdentity{}).Count(&n)
+	return n
+}
+
+func TestOIDCRoundTrip(t *testing.T) {
+	_, router := setupOIDC(t, mockUser)
+
+	status, resp := signIn(t, router)
+	if status != http.StatusOK {
+		t.Fatalf("first login: status %d: %s", status, resp.Error)
+	}
+	user := loginUser(t, resp)
+	if user.Email != mockUser.Email || !user.EmailVerified {
+		t.Errorf("created user = %+v, want verified %s", user, mockUser.Email)
+	}
+
+	var identity models.UserIdentity
+	if err := database.DB.Where("provider = ? AND subject = ?", "mock", mockUser.Subject).First(&identity).Error; err != nil {
+		t.Fatalf("identity not stored: %v", err)
+	}
+	if identity.UserID != user.ID {
+		t.Errorf("identity linked to user %d, want %d", identity.UserID, user.ID)
+	}
+
+	// A known identity signs in to the same account
+	status, resp = signIn(t, router)
+	if status != http.StatusOK {
+		t.Fatalf("second login: status %d: %s", status, resp.Error)
+	}
+	if again := loginUser(t, resp); again.ID != user.ID {
+		t.Errorf("second login returned user %d, want %d", again.ID, user.ID)
+	}
+	if n := countIdentities(t); n != 1 {
+		t.Errorf("%d identities stored, want 1", n)
+	}
+}
+
+func TestOIDCCallbackRejectsBadState(t *testing.T) {
+	_, router := setupOIDC(t, mockUser)
+
+	code, state := authorize(t, router)
+	if status, _ := callback(t, router, code, "not-the-state"); status != http.StatusBadRequest {
+		t.Errorf("unknown state: status %d, want %d", status, http.StatusBadRequest)
+	}
+
+	if status, resp := callback(t, router, code, state); status != http.StatusOK {
+		t.Fatalf("valid state: status %d: %s", status, resp.Error)
+	}
+	if status, _ := callback(t, router, code, state); status != http.StatusBadRequest {
+		t.Errorf("replayed state: status %d, want %d", status, http.StatusBadRequest)
+	}
+}
+
+func TestOIDCCallbackRejectsExpiredState(t *testing.T) {
+	_, router := setupOIDC(t, mockUser)
+
+	code, state := authorize(t, router)
+	database.DB.Model(&models.OIDCAuthRequest{}).Where("1 = 1").Update("expires_at", time.Now().Add(-time.Minute))
+
+	if status, _ := callback(t, router, code, state); status != http.StatusBadRequest {
+		t.Errorf("expired state: status %d, want %d", status, http.StatusBadRequest)
+	}
+}
+
+func TestOIDCCallbackRejectsWrongIssuer(t *testing.T) {
+	server, router := setupOIDC(t, mockUser)
+
+	// Discovery is cached by authorize, so only the ID token changes
+	code, state := authorize(t, router)
+	server.Issuer = "https://attacker.example"
+
+	if status, _ := callback(t, router, code, state); status != http.StatusUnauthorized {
+		t.Errorf("wrong issuer: status %d, want %d", status, http.StatusUnauthorized)
+	}
+	if n := countIdentities(t); n != 0 {
+		t.Errorf("%d identities stored after rejected login, want 0", n)
+	}
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	server, _ := setupOIDC(t, mockUser)
+	provider, _ := oidc.GetProvider("mock")
+	ctx := context.Background()
+	if _, err := provider.Discover(ctx); err != nil {
+		t.Fatalf("discover: %v", err)
+	}
+
+	sign := func(nonce string, now time.Time) string {
+		token, err := server.SignIDToken(mockUser, nonce, now)
+		if err != nil {
+			t.Fatalf("sign token: %v", err)
+		}
+		return token
+	}
+
+	valid := sign("nonce-1", time.Now())
+	other := sign("nonce-2", time.Now())
+	tampered := valid[:strings.LastIndex(valid, ".")] + other[strings.LastIndex(other, "."):]
+
+	issuer := server.Issuer
+	server.Issuer = "https://attacker.example"
+	wrongIssuer := sign("nonce-1", time.Now())
+	server.Issuer = issuer
+
+	server.ClientID = "someone-else"
+	wrongAudience := sign("nonce-1", time.Now())
+	server.ClientID = testClientID
+
+	tests := []struct {
+		name    string
+		token   string
+		nonce   string
+		wantErr bool
+	}{
+		{"valid", valid, "nonce-1", false},
+		{"nonce mismatch", valid, "nonce-2", true},
+		{"missing nonce", valid, "", true},
+		{"wrong issuer", wrongIssuer, "nonce-1", true},
+		{"wrong audience", wrongAudience, "nonce-1", true},
+		{"expired", sign("nonce-1", time.Now().Add(-time.Hour)), "nonce-1", true},
+		{"bad signature", tampered, "nonce-1", true},
+		{"not a token", "garbage", "nonce-1", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := provider.VerifyIDToken(ctx, tt.token, tt.nonce)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("token accepted, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("valid token rejected: %v", err)
+			}
+			if claims.Subject != mockUser.Subject || claims.Email != mockUser.Email {
+				t.Errorf("claims = %+v", claims)
+			}
+		})
+	}
+}
+
+func TestOIDCLinksVerifiedLocalAccount(t *testing.T) {
+	_, router := setupOIDC(t, mockUser)
+	local := createLocalUser(t, mockUser.Email, true)
+
+	status, resp := signIn(t, router)
+	if status != http.StatusOK {
+		t.Fatalf("login: status %d: %s", status, resp.Error)
+	}
+	if user := loginUser(t, resp); user.ID != local.ID {
+		t.Errorf("signed in as user %d, want existing user %d", user.ID, local.ID)
+	}
+
+	var identity models.UserIdentity
+	if err := database.DB.Where("subject = ?", mockUser.Subject).First(&identity).Error; err != nil {
+		t.Fatalf("identity not linked: %v", err)
+	}
+	if identity.UserID != local.ID {
+		t.Errorf("identity linked to user %d, want %d", identity.UserID, local.ID)
+	}
+}
+
+func TestOIDCRefusesUnverifiedLocalAccount(t *testing.T) {
+	_, router := setupOIDC(t, mockUser)
+	// Someone registered the address without proving they own it
+	squatter := createLocalUser(t, mockUser.Email, false)
+
+	status, resp := signIn(t, router)
+	if status != http.StatusConflict {
+		t.Fatalf("login: status %d (%s), want %d", status, resp.Error, http.StatusConflict)
+	}
+	if n := countIdentities(t); n != 0 {
+		t.Errorf("%d identities stored, want 0", n)
+	}
+
+	var user models.User
+	database.DB.First(&user, squatter.ID)
+	if user.EmailVerified {
+		t.Error("unverified account was marked verified")
+	}
+	if user.Password != squatter.Password {
+		t.Error("local password was changed")
+	}
+}
+
+func TestOIDCRejectsUnverifiedProviderEmail(t *testing.T) {
+	unverified := mockUser
+	unverified.EmailVerified = false
+	_, router := setupOIDC(t, unverified)
+	createLocalUser(t, unverified.Email, true)
+
+	if status, _ := signIn(t, router); status != http.StatusForbidden {
+		t.Errorf("login: status %d, want %d", status, http.StatusForbidden)
+	}
+	if n := countIdentities(t); n != 0 {
+		t.Errorf("%d identities stored, want 0", n)
+	}
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"net/http"
+
+	"health-tracker/database"
+	"health-tracker/models"
+	"health-tracker/units"
+	"health-tracker/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetUnitPreferences returns the user's display units
+func GetUnitPreferences(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	utils.SuccessResponse(c, http.StatusOK, "Unit preferences retrieved", findUnitPreference(userID))
+}
+
+// UpdateUnitPreferences changes the user's display units. Omitted fields
+// keep their current value.
+func UpdateUnitPreferences(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var req models.UnitPreferenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	pref := findUnitPreference(userID)
+	if req.WeightUnit != "" {
+		pref.WeightUnit = req.WeightUnit
+	}
+	if req.HeightUnit != "" {
+		pref.HeightUnit = req.HeightUnit
+	}
+	if req.VolumeUnit != "" {
+		pref.VolumeUnit = req.VolumeUnit
+	}
+
+	if result := database.DB.Save(&pref); result.Error != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update unit preferences")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Unit preferences updated", pref)
+}
+
+// findUnitPreference loads the user's preference record, or an unsaved
+// metric one if they have none
+func findUnitPreference(userID uint) models.UnitPreference {
+	var pref models.UnitPreference
+	if result := database.DB.Where("user_id = ?", userID).First(&pref); result.Error != nil {
+		return models.UnitPreference{
+			UserID:     userID,
+			WeightUnit: units.Metric.Weight,
+			HeightUnit: units.Metric.Height,
+			VolumeUnit: units.Metric.Volume,
+		}
+	}
+	return pref
+}
+
+// loadUnits returns the units a user's responses are converted to
+func loadUnits(userID uint) units.Preferences {
+	return findUnitPreference(userID).Units()
+}
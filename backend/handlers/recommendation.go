@@ -1,7 +1,12 @@
 package handlers
 
 import (
+	"fmt"
+	"math"
 	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
 
 	"health-tracker/database"
 	"health-tracker/models"
@@ -22,7 +27,7 @@ func GetFoodRecommendations(c *gin.Context) {
 	var symptoms []models.Symptom
 	database.DB.Where("user_id = ?", userID).Order("logged_at desc").Limit(10).Find(&symptoms)
 
-	recommendations := generateFoodRecommendations(health, symptoms)
+	recommendations := generateFoodRecommendations(health, classifyBMI(userID, health).Category, symptoms)
 
 	utils.SuccessResponse(c, http.StatusOK, "Food recommendations retrieved", recommendations)
 }
@@ -34,6 +39,7 @@ func GetExerciseRecommendations(c *gin.Context) {
 	// Get user profile
 	var user models.User
 	database.DB.First(&user, userID)
+	refreshActivityLevel(&user)
 
 	// Get latest health data
 	var health models.HealthData
@@ -61,7 +67,7 @@ func GetEmotionalRecommendations(c *gin.Context) {
 	database.DB.Where("user_id = ? AND symptom_type = ?", userID, "mental").
 		Order("logged_at desc").Limit(5).Find(&mentalSymptoms)
 
-	recommendations := generateEmotionalRecommendations(health.EmotionalState, mentalSymptoms)
+	recommendations := generateEmotionalRecommendations(health.EmotionalState, mentalSymptoms, loadSleepStats(userID, 7))
 
 	utils.SuccessResponse(c, http.StatusOK, "Emotional recommendations retrieved", recommendations)
 }
@@ -70,6 +76,10 @@ func GetEmotionalRecommendations(c *gin.Context) {
 func GetDailyMenu(c *gin.Context) {
 	userID := c.GetUint("userID")
 
+	var user models.User
+	database.DB.First(&user, userID)
+	refreshActivityLevel(&user)
+
 	// Get latest health data
 	var health models.HealthData
 	database.DB.Where("user_id = ?", userID).Order("record_date desc").First(&health)
@@ -78,14 +88,13 @@ func GetDailyMenu(c *gin.Context) {
 	var symptoms []models.Symptom
 	database.DB.Where("user_id = ?", userID).Order("logged_at desc").Limit(10).Find(&symptoms)
 
-	menu := generateDailyMenu(health, symptoms)
+	menu := generateDailyMenu(classifyBMI(userID, health).Category, symptoms)
+	scaleDailyMenu(&menu, computeEnergyTargets(user).Target.Calories)
 
 	utils.SuccessResponse(c, http.StatusOK, "Daily menu generated", menu)
 }
 
-func generateDailyMenu(health models.HealthData, symptoms []models.Symptom) models.DailyMenu {
-	bmiCategory := models.GetBMICategory(health.BMI)
-	
+func generateDailyMenu(bmiCategory string, symptoms []models.Symptom) models.DailyMenu {
 	// Check symptoms
 	symptomNames := make(map[string]bool)
 	for _, s := range symptoms {
@@ -645,10 +654,65 @@ func generateDailyMenu(health models.HealthData, symptoms []models.Symptom) mode
 	return menu
 }
 
-func generateFoodRecommendations(health models.HealthData, symptoms []models.Symptom) []models.FoodRecommendation {
-	var recommendations []models.FoodRecommendation
+var (
+	calorieLabel   = regexp.MustCompile(`(\d+)\s*kkal`)
+	ingredientMass = regexp.MustCompile(`(\d+(?:[.,]\d+)?)\s*(g|ml)\b`)
+)
+
+// scaleDailyMenu scales the menu so the main meals and snacks add up to
+// the user's calorie target. Only gram and millilitre amounts in the
+// ingredients are scaled; counts such as "1 butir" are kept. The factor
+// stays between 0.5 and 2 so the recipes remain realistic.
+func scaleDailyMenu(menu *models.DailyMenu, targetCalories float64) {
+	base := mealPlanCalories(menu.Breakfast) + mealPlanCalories(menu.Lunch) + mealPlanCalories(menu.Dinner)
+	for _, snack := range menu.Snacks {
+		base += mealPlanCalories(snack)
+	}
+	if base == 0 || targetCalories <= 0 {
+		return
+	}
+
+	factor := math.Round(math.Max(0.5, math.Min(2, targetCalories/base))*100) / 100
+
+	scaleMealPlan(&menu.Breakfast, factor)
+	scaleMealPlan(&menu.Lunch, factor)
+	scaleMealPlan(&menu.Dinner, factor)
+	for _, plans := range [][]models.MealPlan{menu.Snacks, menu.BreakfastAlt, menu.LunchAlt, menu.DinnerAlt} {
+		for i := range plans {
+			scaleMealPlan(&plans[i], factor)
+		}
+	}
+
+	menu.TotalCalories = fmt.Sprintf("~%.0f kkal", math.Round(base*factor/10)*10)
+	menu.TargetCalories = targetCalories
+	menu.PortionFactor = factor
+}
+
+func mealPlanCalories(plan models.MealPlan) float64 {
+	m := calorieLabel.FindStringSubmatch(plan.Calories)
+	if m == nil {
+		return 0
+	}
+	kcal, _ := strconv.ParseFloat(m[1], 64)
+	return kcal
+}
+
+func scaleMealPlan(plan *models.MealPlan, factor float64) {
+	if kcal := mealPlanCalories(*plan); kcal > 0 {
+		plan.Calories = fmt.Sprintf("~%.0f kkal", math.Round(kcal*factor/10)*10)
+	}
+
+	for i, ingredient := range plan.Ingredients {
+		plan.Ingredients[i] = ingredientMass.ReplaceAllStringFunc(ingredient, func(amount string) string {
+			m := ingredientMass.FindStringSubmatch(amount)
+			qty, _ := strconv.ParseFloat(strings.Replace(m[1], ",", ".", 1), 64)
+			return fmt.Sprintf("%.0f%s", math.Max(5, math.Round(qty*factor/5)*5), m[2])
+		})
+	}
+}
 
-	bmiCategory := models.GetBMICategory(health.BMI)
+func generateFoodRecommendations(health models.HealthData, bmiCategory string, symptoms []models.Symptom) []models.FoodRecommendation {
+	var recommendations []models.FoodRecommendation
 
 	// BMI-based recommendations
 	switch bmiCategory {
@@ -886,12 +950,13 @@ func generateFoodRecommendations(health models.HealthData, symptoms []models.Sym
 func generateExerciseRecommendations(user models.User, health models.HealthData, symptoms []models.Symptom) []models.ExerciseRecommendation {
 	var recommendations []models.ExerciseRecommendation
 
+	// Logged workouts take precedence over the self-reported level
 	activityLevel := health.ActivityLevel
-	if activityLevel == "" {
+	if activityLevel == "" || user.ActivityLevelSource == models.ActivitySourceWorkouts {
 		activityLevel = user.ActivityLevel
 	}
 
-	bmiCategory := models.GetBMICategory(health.BMI)
+	bmiCategory := classifyBMI(user.ID, health).Category
 
 	// Activity level based
 	switch activityLevel {
@@ -1046,7 +1111,7 @@ func generateExerciseRecommendations(user models.User, health models.HealthData,
 	return recommendations
 }
 
-func generateEmotionalRecommendations(emotionalState string, mentalSymptoms []models.Symptom) []models.EmotionalRecommendation {
+func generateEmotionalRecommendations(emotionalState string, mentalSymptoms []models.Symptom, sleep models.SleepStats) []models.EmotionalRecommendation {
 	var recommendations []models.EmotionalRecommendation
 
 	// Based on emotional state
@@ -1174,5 +1239,27 @@ func generateEmotionalRecommendations(emotionalState string, mentalSymptoms []mo
 		}
 	}
 
+	// Based on logged sleep in the last week
+	if sleep.NightsLogged > 0 && (sleep.AverageHours < sleep.TargetHours-1 || sleep.SleepDebtHours >= 5) {
+		recommendations = append(recommendations, models.EmotionalRecommendation{
+			EmotionalState: "sleep_debt",
+			Title:          "🛌 Bayar Utang Tidur Anda",
+			Description:    "Waktu tidur Anda minggu ini kurang dari target",
+			Activities:     []string{"Tidur 30-60 menit lebih awal", "Power nap 20 menit di siang hari", "Hindari gadget 1 jam sebelum tidur", "Relaksasi otot progresif sebelum tidur"},
+			Tips:           []string{"Tambah waktu tidur bertahap, jangan sekaligus", "Hindari kafein setelah jam 2 siang", "Jangan tidur siang lebih dari 30 menit", "Prioritaskan tidur di akhir pekan tanpa begadang"},
+			Reason:         fmt.Sprintf("Rata-rata tidur Anda %.1f jam per malam dengan utang tidur %.1f jam dari target %.0f jam.", sleep.AverageHours, sleep.SleepDebtHours, sleep.TargetHours),
+		})
+	}
+	if sleep.NightsLogged >= 3 && sleep.ConsistencyScore < 50 {
+		recommendations = append(recommendations, models.EmotionalRecommendation{
+			EmotionalState: "sleep_schedule",
+			Title:          "⏰ Atur Jadwal Tidur yang Konsisten",
+			Description:    "Jam tidur dan bangun Anda masih berubah-ubah",
+			Activities:     []string{"Pasang alarm tidur, bukan hanya alarm bangun", "Rutinitas malam yang sama setiap hari", "Terpapar sinar matahari pagi setelah bangun"},
+			Tips:           []string{"Bangun di jam yang sama termasuk akhir pekan", "Selisih jam tidur maksimal 30 menit", "Hindari makan berat 2-3 jam sebelum tidur"},
+			Reason:         fmt.Sprintf("Skor konsistensi tidur Anda %d dari 100. Jadwal yang teratur membantu kualitas tidur dan suasana hati.", sleep.ConsistencyScore),
+		})
+	}
+
 	return recommendations
 }
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"health-tracker/config"
+	"health-tracker/database"
+	"health-tracker/models"
+	"health-tracker/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// dashboardScoreDays is the length of the score history on the dashboard
+const dashboardScoreDays = 14
+
+// GetHealthScore returns today's health score with its per-component
+// breakdown and the score for each of the last `days` days (default 30)
+func GetHealthScore(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	days, err := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if err != nil || days < 7 || days > 90 {
+		utils.ErrorResponse(c, http.StatusBadRequest, "days must be between 7 and 90")
+		return
+	}
+
+	score, history := loadHealthScores(userID, days)
+	utils.SuccessResponse(c, http.StatusOK, "Health score retrieved", gin.H{
+		"score":      score.Score,
+		"components": score.Components,
+		"history":    history,
+	})
+}
+
+// loadHealthScores scores each of the last `days` days from the data the
+// user had logged by the end of that day. It returns today's score and the
+// history, oldest first.
+func loadHealthScores(userID uint, days int) (models.HealthScore, []models.HealthScorePoint) {
+	weights, _ := config.AppConfig.ScoreWeights() // checked at startup
+	engine := models.NewHealthScoreEngine(weights, models.DefaultHealthScoreComponents...)
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	first := today.AddDate(0, 0, -(days - 1))
+	moodFrom := first.AddDate(0, 0, -13)
+	weekFrom := first.AddDate(0, 0, -6)
+	end := today.AddDate(0, 0, 1)
+
+	var records []models.HealthData
+	database.DB.Where("user_id = ? AND record_date >= ? AND record_date < ?", userID, moodFrom, end).
+		Order("record_date asc, id asc").Find(&records)
+	var prior models.HealthData
+	database.DB.Where("user_id = ? AND record_date < ?", userID, moodFrom).Order("record_date desc").First(&prior)
+
+	var symptoms []models.Symptom
+	database.DB.Where("user_id = ? AND logged_at >= ? AND logged_at < ?", userID, weekFrom, end).Find(&symptoms)
+
+	// Today's intake is left out until the day is over
+	var water []models.WaterIntake
+	database.DB.Where("user_id = ? AND date >= ? AND date < ?", userID, weekFrom.Format("2006-01-02"), today.Format("2006-01-02")).
+		Find(&water)
+
+	var workouts []models.Workout
+	database.DB.Where("user_id = ? AND performed_at >= ? AND performed_at < ?", userID, weekFrom, end).Find(&workouts)
+
+	// A day of margin for sessions logged in other time zones; nights
+	// are matched on their local wake date below
+	var sessions []models.SleepSession
+	database.DB.Where("user_id = ? AND wake_time >= ?", userID, weekFrom.AddDate(0, 0, -1)).
+		Order("wake_time asc").Find(&sessions)
+	sleepTarget := sleepTargetHours(userID)
+
+	categories := make(map[uint]string)
+	var score models.HealthScore
+	history := make([]models.HealthScorePoint, 0, days)
+	for day := first; !day.After(today); day = day.AddDate(0, 0, 1) {
+		dayEnd := day.AddDate(0, 0, 1)
+		weekStart := day.AddDate(0, 0, -6)
+		from, to := weekStart.Format("2006-01-02"), day.Format("2006-01-02")
+
+		in := models.HealthScoreInput{Day: dayEnd}
+		if dayEnd.After(now) {
+			in.Day = now
+		}
+
+		latest := prior
+		for _, r := range records {
+			if !r.RecordDate.Before(dayEnd) {
+				break
+			}
+			latest = r
+			if r.EmotionalState != "" && !r.RecordDate.Before(day.AddDate(0, 0, -13)) {
+				in.Moods = append(in.Moods, r)
+			}
+		}
+		if latest.ID != 0 {
+			category, ok := categories[latest.ID]
+			if !ok {
+				category = classifyBMI(userID, latest).Category
+				categories[latest.ID] = category
+			}
+			in.BMICategory = category
+			in.ActivityLevel = latest.ActivityLevel
+		}
+
+		for _, s := range symptoms {
+			if !s.LoggedAt.Before(weekStart) && s.LoggedAt.Before(dayEnd) {
+				in.Symptoms = append(in.Symptoms, s)
+			}
+		}
+		for _, w := range water {
+			if w.Date >= from && w.Date <= to {
+				in.Water = append(in.Water, w)
+			}
+		}
+		for _, w := range workouts {
+			if !w.PerformedAt.Before(weekStart) && w.PerformedAt.Before(dayEnd) {
+				in.Workouts = append(in.Workouts, w)
+			}
+		}
+		var nights []models.SleepSession
+		for _, s := range sessions {
+			if wake := s.Local(s.WakeTime).Format("2006-01-02"); wake >= from && wake <= to {
+				nights = append(nights, s)
+			}
+		}
+		in.Sleep = summarizeSleep(nights, sleepTarget)
+
+		score = engine.Evaluate(in)
+		history = append(history, models.HealthScorePoint{Date: to, Score: score.Score})
+	}
+	return score, history
+}
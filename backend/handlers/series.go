@@ -0,0 +1,397 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"health-tracker/database"
+	"health-tracker/models"
+	"health-tracker/units"
+	"health-tracker/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// maxSeriesBuckets keeps a single request from scanning years of data
+// at day resolution
+const maxSeriesBuckets = 1000
+
+var seriesAggregations = map[string]string{
+	"avg":  "AVG",
+	"min":  "MIN",
+	"max":  "MAX",
+	"last": "",
+}
+
+// seriesSource says where a metric's samples are stored
+type seriesSource struct {
+	model           interface{}
+	timeColumn      string
+	valueColumn     string
+	secondaryColumn string
+	where           string
+	args            []interface{}
+	unit            string
+	secondaryName   string
+	convert         func(float64) float64
+}
+
+// seriesRow is one aggregated bucket as returned by the database
+type seriesRow struct {
+	Bucket    string
+	Value     *float64
+	Secondary *float64
+	Samples   int
+}
+
+// GetHealthSeries returns a metric aggregated into time buckets. Query:
+// metric (weight, bmi or a vital type), from and to (YYYY-MM-DD, UTC,
+// default the last 30 days), bucket (day/week/month), agg
+// (avg/min/max/last), fill (none/null/previous/linear), ma (trailing
+// moving average over N buckets) and context for vitals.
+func GetHealthSeries(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	metric := c.Query("metric")
+	source, ok := seriesSourceFor(metric, c.Query("context"), loadUnits(userID))
+	if !ok {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Unknown metric, use weight, bmi or a vital type")
+		return
+	}
+
+	bucket := c.DefaultQuery("bucket", "day")
+	if bucket != "day" && bucket != "week" && bucket != "month" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid bucket, use day, week or month")
+		return
+	}
+	agg := c.DefaultQuery("agg", "avg")
+	if _, ok := seriesAggregations[agg]; !ok {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid agg, use avg, min, max or last")
+		return
+	}
+	fill := c.DefaultQuery("fill", "none")
+	if fill != "none" && fill != "null" && fill != "previous" && fill != "linear" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid fill, use none, null, previous or linear")
+		return
+	}
+	window := 0
+	if ma := c.Query("ma"); ma != "" {
+		n, err := strconv.Atoi(ma)
+		if err != nil || n < 2 || n > 90 {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ma, use a window of 2 to 90 buckets")
+			return
+		}
+		window = n
+	}
+
+	to := time.Now().UTC().Truncate(24 * time.Hour)
+	from := to.AddDate(0, 0, -29)
+	if v := c.Query("from"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid from date, use YYYY-MM-DD")
+			return
+		}
+		from = t
+	}
+	if v := c.Query("to"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid to date, use YYYY-MM-DD")
+			return
+		}
+		to = t
+	}
+	if to.Before(from) {
+		utils.ErrorResponse(c, http.StatusBadRequest, "to must not be before from")
+		return
+	}
+
+	start := bucketStart(from, bucket)
+	if len(bucketKeys(start, to, bucket)) > maxSeriesBuckets {
+		utils.ErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("Range has more than %d buckets, use a larger bucket", maxSeriesBuckets))
+		return
+	}
+
+	// Load extra buckets before from so the first moving averages and
+	// filled values have history to work with
+	queryStart := start
+	if window > 1 {
+		queryStart = addBuckets(start, bucket, -(window - 1))
+	}
+
+	rows, err := querySeries(source, userID, bucket, agg, queryStart, to.AddDate(0, 0, 1))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to load series")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Series retrieved", models.Series{
+		Metric:        metric,
+		Unit:          source.unit,
+		SecondaryName: source.secondaryName,
+		From:          from.Format("2006-01-02"),
+		To:            to.Format("2006-01-02"),
+		Bucket:        bucket,
+		Aggregation:   agg,
+		Fill:          fill,
+		MovingAverage: window,
+		Points:        buildSeries(rows, source, bucketKeys(queryStart, to, bucket), start, fill, window),
+	})
+}
+
+// seriesSourceFor maps a metric name to its table and columns. Weight is
+// converted to the user's unit; vitals keep the unit they are stored in.
+func seriesSourceFor(metric, context string, prefs units.Preferences) (seriesSource, bool) {
+	switch metric {
+	case "weight":
+		unit := prefs.Weight
+		if unit == "" {
+			unit = units.Kilogram
+		}
+		return seriesSource{
+			model:       &models.HealthData{},
+			timeColumn:  "record_date",
+			valueColumn: "weight_kg",
+			unit:        unit,
+			convert:     func(kg float64) float64 { return units.FromKg(kg, unit) },
+		}, true
+	case "bmi":
+		return seriesSource{
+			model:       &models.HealthData{},
+			timeColumn:  "record_date",
+			valueColumn: "bmi",
+			unit:        "kg/m2",
+		}, true
+	}
+
+	vt, ok := models.GetVitalType(metric)
+	if !ok {
+		return seriesSource{}, false
+	}
+	source := seriesSource{
+		model:       &models.VitalSign{},
+		timeColumn:  "measured_at",
+		valueColumn: "value",
+		where:       "type = ?",
+		args:        []interface{}{metric},
+		unit:        vt.Unit,
+	}
+	if vt.SecondaryName != "" {
+		source.secondaryColumn = "secondary_value"
+		source.secondaryName = vt.SecondaryName
+	}
+	if context != "" {
+		source.where += " AND context = ?"
+		source.args = append(source.args, context)
+	}
+	return source, true
+}
+
+// querySeries aggregates in the database so only one row per bucket is
+// returned. "last" picks the newest sample per bucket with ROW_NUMBER,
+// which both SQLite (3.25+) and Postgres support.
+func querySeries(source seriesSource, userID uint, bucket, agg string, from, to time.Time) ([]seriesRow, error) {
+	expr := seriesBucketExpr(database.DB.Dialector.Name(), bucket, source.timeColumn)
+
+	scope := func(db *gorm.DB) *gorm.DB {
+		db = db.Model(source.model).
+			Where("user_id = ? AND "+source.timeColumn+" >= ? AND "+source.timeColumn+" < ?", userID, from, to)
+		if source.where != "" {
+			db = db.Where(source.where, source.args...)
+		}
+		return db
+	}
+
+	var rows []seriesRow
+	if agg == "last" {
+		secondary := "NULL"
+		if source.secondaryColumn != "" {
+			secondary = source.secondaryColumn
+		}
+		ranked := scope(database.DB).Select(fmt.Sprintf(
+			"%s AS bucket, %s AS value, %s AS secondary, "+
+				"ROW_NUMBER() OVER (PARTITION BY %s ORDER BY %s DESC, id DESC) AS rn, "+
+				"COUNT(*) OVER (PARTITION BY %s) AS samples",
+			expr, source.valueColumn, secondary, expr, source.timeColumn, expr))
+		err := database.DB.Table("(?) AS ranked", ranked).
+			Select("bucket, value, secondary, samples").
+			Where("rn = 1").Order("bucket").Scan(&rows).Error
+		return rows, err
+	}
+
+	fn := seriesAggregations[agg]
+	secondary := "NULL"
+	if source.secondaryColumn != "" {
+		secondary = fn + "(" + source.secondaryColumn + ")"
+	}
+	err := scope(database.DB).
+		Select(fmt.Sprintf("%s AS bucket, %s(%s) AS value, %s AS secondary, COUNT(*) AS samples",
+			expr, fn, source.valueColumn, secondary)).
+		Group("bucket").Order("bucket").Scan(&rows).Error
+	return rows, err
+}
+
+// seriesBucketExpr returns SQL giving the first day (YYYY-MM-DD, UTC) of
+// the bucket a timestamp falls in. Weeks start on Monday.
+func seriesBucketExpr(dialect, bucket, column string) string {
+	if dialect == "postgres" {
+		return fmt.Sprintf("to_char(date_trunc('%s', %s AT TIME ZONE 'UTC'), 'YYYY-MM-DD')", bucket, column)
+	}
+
+	// SQLite converts timestamps with an offset to UTC before applying
+	// the modifiers
+	switch bucket {
+	case "week":
+		return fmt.Sprintf("date(%s, 'weekday 0', '-6 days')", column)
+	case "month":
+		return fmt.Sprintf("strftime('%%Y-%%m-01', %s)", column)
+	}
+	return fmt.Sprintf("date(%s)", column)
+}
+
+// bucketStart returns the first day of the bucket containing day
+func bucketStart(day time.Time, bucket string) time.Time {
+	switch bucket {
+	case "week":
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	case "month":
+		return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	return day
+}
+
+func addBuckets(t time.Time, bucket string, n int) time.Time {
+	switch bucket {
+	case "week":
+		return t.AddDate(0, 0, 7*n)
+	case "month":
+		return t.AddDate(0, n, 0)
+	}
+	return t.AddDate(0, 0, n)
+}
+
+// bucketKeys lists the buckets from start (already aligned) through end
+func bucketKeys(start, end time.Time, bucket string) []time.Time {
+	var keys []time.Time
+	for t := start; !t.After(end); t = addBuckets(t, bucket, 1) {
+		keys = append(keys, t)
+		if len(keys) > maxSeriesBuckets+90 {
+			break
+		}
+	}
+	return keys
+}
+
+// buildSeries lays the rows onto the bucket grid, computes moving
+// averages from measured buckets only, fills gaps, and drops the warm-up
+// buckets before start
+func buildSeries(rows []seriesRow, source seriesSource, keys []time.Time, start time.Time, fill string, window int) []models.SeriesPoint {
+	byBucket := make(map[string]seriesRow, len(rows))
+	for _, r := range rows {
+		byBucket[r.Bucket] = r
+	}
+
+	convert := func(v *float64) *float64 {
+		if v == nil {
+			return nil
+		}
+		x := *v
+		if source.convert != nil {
+			x = source.convert(x)
+		}
+		x = units.Round(x, 2)
+		return &x
+	}
+
+	points := make([]models.SeriesPoint, len(keys))
+	values := make([]*float64, len(keys))
+	secondary := make([]*float64, len(keys))
+	for i, k := range keys {
+		key := k.Format("2006-01-02")
+		points[i].Bucket = key
+		if r, ok := byBucket[key]; ok {
+			values[i] = convert(r.Value)
+			secondary[i] = convert(r.Secondary)
+			points[i].Count = r.Samples
+		}
+	}
+
+	if window > 1 {
+		for i := range points {
+			points[i].MovingAverage = trailingMean(values, i, window)
+			if source.secondaryColumn != "" {
+				points[i].SecondaryMoving = trailingMean(secondary, i, window)
+			}
+		}
+	}
+
+	filled := fillSeriesGaps(values, fill)
+	fillSeriesGaps(secondary, fill)
+
+	startKey := start.Format("2006-01-02")
+	result := []models.SeriesPoint{}
+	for i, p := range points {
+		if p.Bucket < startKey || (fill == "none" && values[i] == nil) {
+			continue
+		}
+		p.Value = values[i]
+		p.SecondaryValue = secondary[i]
+		p.Filled = filled[i]
+		result = append(result, p)
+	}
+	return result
+}
+
+// trailingMean averages the non-empty values in the window ending at i
+func trailingMean(values []*float64, i, window int) *float64 {
+	var sum float64
+	var n int
+	for j := i - window + 1; j <= i; j++ {
+		if j >= 0 && values[j] != nil {
+			sum += *values[j]
+			n++
+		}
+	}
+	if n == 0 {
+		return nil
+	}
+	mean := units.Round(sum/float64(n), 2)
+	return &mean
+}
+
+// fillSeriesGaps fills empty buckets in place and reports which were
+// filled. previous carries the last value forward; linear interpolates
+// between the neighbours. Leading and, for linear, trailing gaps stay empty.
+func fillSeriesGaps(values []*float64, fill string) []bool {
+	filled := make([]bool, len(values))
+	if fill != "previous" && fill != "linear" {
+		return filled
+	}
+
+	last := -1
+	for i, v := range values {
+		if v != nil && !filled[i] {
+			if fill == "linear" && last >= 0 && i-last > 1 {
+				a, b := *values[last], *v
+				for j := last + 1; j < i; j++ {
+					x := units.Round(a+(b-a)*float64(j-last)/float64(i-last), 2)
+					values[j] = &x
+					filled[j] = true
+				}
+			}
+			last = i
+			continue
+		}
+		if fill == "previous" && last >= 0 {
+			x := *values[last]
+			values[i] = &x
+			filled[i] = true
+		}
+	}
+	return filled
+}
@@ -0,0 +1,258 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"health-tracker/config"
+	"health-tracker/database"
+	"health-tracker/models"
+	"health-tracker/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var errRefreshTokenReused = errors.New("refresh token reused")
+
+// startSession creates a session for the user and returns the token pair.
+// Signing in again cancels a pending account deletion.
+func startSession(c *gin.Context, user models.User) (models.LoginResponse, error) {
+	if err := cancelAccountDeletion(&user); err != nil {
+		return models.LoginResponse{}, err
+	}
+
+	now := time.Now()
+	session := models.Session{
+		UserID:     user.ID,
+		UserAgent:  truncate(c.Request.UserAgent(), 255),
+		IPAddress:  c.ClientIP(),
+		LastUsedAt: now,
+		ExpiresAt:  now.Add(refreshTokenExpiry()),
+	}
+
+	var refreshToken string
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&session).Error; err != nil {
+			return err
+		}
+		var err error
+		refreshToken, err = createRefreshToken(tx, session.ID)
+		return err
+	})
+	if err != nil {
+		return models.LoginResponse{}, err
+	}
+
+	accessToken, err := utils.GenerateToken(user.ID, user.Email, user.Role, session.ID)
+	if err != nil {
+		return models.LoginResponse{}, err
+	}
+
+	return models.LoginResponse{
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    config.AppConfig.JWTExpiryHours * 3600,
+		User:         user,
+	}, nil
+}
+
+func createRefreshToken(tx *gorm.DB, sessionID uint) (string, error) {
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	record := models.RefreshToken{
+		SessionID: sessionID,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().Add(refreshTokenExpiry()),
+	}
+	if err := tx.Create(&record).Error; err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+func refreshTokenExpiry() time.Duration {
+	return time.Duration(config.AppConfig.RefreshTokenExpiryDays) * 24 * time.Hour
+}
+
+// revokeSession marks a session as revoked if it is still active
+func revokeSession(tx *gorm.DB, sessionID uint, reason string) error {
+	return tx.Model(&models.Session{}).
+		Where("id = ? AND revoked_at IS NULL", sessionID).
+		Updates(map[string]interface{}{"revoked_at": time.Now(), "revoked_reason": reason}).Error
+}
+
+// revokeUserSessions revokes every active session of a user
+func revokeUserSessions(tx *gorm.DB, userID uint, reason string) error {
+	return tx.Model(&models.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Updates(map[string]interface{}{"revoked_at": time.Now(), "revoked_reason": reason}).Error
+}
+
+// RefreshAccessToken rotates a refresh token and issues a new access token
+func RefreshAccessToken(c *gin.Context) {
+	var req models.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	var stored models.RefreshToken
+	if result := database.DB.Where("token_hash = ?", utils.HashToken(req.RefreshToken)).First(&stored); result.Error != nil {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid refresh token")
+		return
+	}
+
+	var session models.Session
+	if result := database.DB.First(&session, stored.SessionID); result.Error != nil || !session.IsActive() {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Session has expired or been revoked")
+		return
+	}
+
+	if stored.UsedAt != nil {
+		handleRefreshTokenReuse(session)
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Refresh token has already been used, session revoked")
+		return
+	}
+
+	if time.Now().After(stored.ExpiresAt) {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Refresh token expired")
+		return
+	}
+
+	var user models.User
+	if result := database.DB.First(&user, session.UserID); result.Error != nil {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	var newRefreshToken string
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&models.RefreshToken{}).
+			Where("id = ? AND used_at IS NULL", stored.ID).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errRefreshTokenReused
+		}
+
+		if err := tx.Model(&session).Updates(map[string]interface{}{
+			"last_used_at": now,
+			"expires_at":   now.Add(refreshTokenExpiry()),
+			"ip_address":   c.ClientIP(),
+		}).Error; err != nil {
+			return err
+		}
+
+		var err error
+		newRefreshToken, err = createRefreshToken(tx, session.ID)
+		return err
+	})
+
+	if errors.Is(err, errRefreshTokenReused) {
+		handleRefreshTokenReuse(session)
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Refresh token has already been used, session revoked")
+		return
+	}
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to refresh session")
+		return
+	}
+
+	accessToken, err := utils.GenerateToken(user.ID, user.Email, user.Role, session.ID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to generate token")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Token refreshed", models.LoginResponse{
+		Token:        accessToken,
+		RefreshToken: newRefreshToken,
+		ExpiresIn:    config.AppConfig.JWTExpiryHours * 3600,
+		User:         user,
+	})
+}
+
+// handleRefreshTokenReuse revokes a session whose rotated refresh token was
+// presented again, which means the token chain has leaked
+func handleRefreshTokenReuse(session models.Session) {
+	log.Printf("⚠️  Refresh token reuse detected for session %d (user %d), revoking session", session.ID, session.UserID)
+	revokeSession(database.DB, session.ID, models.SessionRevokedTokenReuse)
+}
+
+// Logout revokes the session of the current access token
+func Logout(c *gin.Context) {
+	sessionID := c.GetUint("sessionID")
+
+	if err := revokeSession(database.DB, sessionID, models.SessionRevokedLogout); err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to logout")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Logout successful", nil)
+}
+
+// GetSessions lists the user's active sessions
+func GetSessions(c *gin.Context) {
+	userID := c.GetUint("userID")
+	currentSessionID := c.GetUint("sessionID")
+
+	var sessions []models.Session
+	database.DB.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_used_at desc").Find(&sessions)
+
+	response := make([]models.SessionResponse, len(sessions))
+	for i, s := range sessions {
+		response[i] = models.SessionResponse{
+			ID:         s.ID,
+			UserAgent:  s.UserAgent,
+			IPAddress:  s.IPAddress,
+			LastUsedAt: s.LastUsedAt,
+			ExpiresAt:  s.ExpiresAt,
+			CreatedAt:  s.CreatedAt,
+			Current:    s.ID == currentSessionID,
+		}
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Sessions retrieved", response)
+}
+
+// RevokeSession signs out one of the user's sessions
+func RevokeSession(c *gin.Context) {
+	userID := c.GetUint("userID")
+	sessionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid session ID")
+		return
+	}
+
+	var session models.Session
+	if result := database.DB.Where("id = ? AND user_id = ? AND revoked_at IS NULL", sessionID, userID).First(&session); result.Error != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Session not found")
+		return
+	}
+
+	if err := revokeSession(database.DB, session.ID, models.SessionRevokedByUser); err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to revoke session")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Session revoked", nil)
+}
+
+func truncate(s string, max int) string {
+	if len(s) > max {
+		return s[:max]
+	}
+	return s
+}
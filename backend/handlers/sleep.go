@@ -0,0 +1,351 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"health-tracker/config"
+	"health-tracker/database"
+	"health-tracker/models"
+	"health-tracker/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxSleepSessionHours rejects sessions that are almost certainly a typo
+// in the bedtime or wake time date
+const maxSleepSessionHours = 24
+
+// LogSleep records a sleep session
+func LogSleep(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var req models.SleepSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	session := models.SleepSession{UserID: userID}
+	if !applySleepRequest(c, &session, req) {
+		return
+	}
+
+	if result := database.DB.Create(&session); result.Error != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to save sleep session")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Sleep session logged", session)
+}
+
+// GetSleepHistory lists sleep sessions by wake time, newest first.
+// Filters: from and to (YYYY-MM-DD) with page and limit.
+func GetSleepHistory(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	query := database.DB.Model(&models.SleepSession{}).Where("user_id = ?", userID)
+
+	if from := c.Query("from"); from != "" {
+		t, err := time.ParseInLocation("2006-01-02", from, time.Local)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid from date, use YYYY-MM-DD")
+			return
+		}
+		query = query.Where("wake_time >= ?", t)
+	}
+	if to := c.Query("to"); to != "" {
+		t, err := time.ParseInLocation("2006-01-02", to, time.Local)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid to date, use YYYY-MM-DD")
+			return
+		}
+		query = query.Where("wake_time < ?", t.AddDate(0, 0, 1))
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "30"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 200 {
+		limit = 30
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var sessions []models.SleepSession
+	query.Order("wake_time desc, id desc").Offset((page - 1) * limit).Limit(limit).Find(&sessions)
+
+	utils.SuccessResponse(c, http.StatusOK, "Sleep history retrieved", gin.H{
+		"sessions": sessions,
+		"total":    total,
+		"page":     page,
+		"limit":    limit,
+	})
+}
+
+// GetSleepStats returns sleep statistics for the last `days` days (default 7)
+func GetSleepStats(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	days, err := strconv.Atoi(c.DefaultQuery("days", "7"))
+	if err != nil || days < 1 || days > 90 {
+		utils.ErrorResponse(c, http.StatusBadRequest, "days must be between 1 and 90")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Sleep stats retrieved", loadSleepStats(userID, days))
+}
+
+// UpdateSleep edits a sleep session
+func UpdateSleep(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var session models.SleepSession
+	if result := database.DB.Where("id = ? AND user_id = ?", c.Param("id"), userID).First(&session); result.Error != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Sleep session not found")
+		return
+	}
+
+	var req models.SleepSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	if !applySleepRequest(c, &session, req) {
+		return
+	}
+
+	if result := database.DB.Save(&session); result.Error != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update sleep session")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Sleep session updated", session)
+}
+
+// DeleteSleep removes a sleep session
+func DeleteSleep(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	result := database.DB.Where("id = ? AND user_id = ?", c.Param("id"), userID).Delete(&models.SleepSession{})
+	if result.Error != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to delete sleep session")
+		return
+	}
+	if result.RowsAffected == 0 {
+		utils.ErrorResponse(c, http.StatusNotFound, "Sleep session not found")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Sleep session deleted", nil)
+}
+
+// applySleepRequest validates req and copies it onto session. Sessions may
+// not overlap another session of the same user. It writes the error
+// response and returns false when the request is invalid.
+func applySleepRequest(c *gin.Context, session *models.SleepSession, req models.SleepSessionRequest) bool {
+	bedtime, wakeTime, err := parseSleepTimes(req.Bedtime, req.WakeTime)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid sleep session: "+err.Error())
+		return false
+	}
+
+	var overlapping int64
+	database.DB.Model(&models.SleepSession{}).
+		Where("user_id = ? AND id <> ? AND bedtime < ? AND wake_time > ?", session.UserID, session.ID, wakeTime, bedtime).
+		Count(&overlapping)
+	if overlapping > 0 {
+		utils.ErrorResponse(c, http.StatusConflict, "Sleep session overlaps an existing session")
+		return false
+	}
+
+	session.Bedtime = bedtime
+	session.WakeTime = wakeTime
+	session.DurationMinutes = int(wakeTime.Sub(bedtime).Minutes())
+	_, offset := wakeTime.Zone()
+	session.UTCOffsetMin = offset / 60
+	session.Quality = req.Quality
+	session.Awakenings = req.Awakenings
+	session.Notes = req.Notes
+	return true
+}
+
+func parseSleepTimes(bedtimeValue, wakeTimeValue string) (time.Time, time.Time, error) {
+	bedtime, err := time.Parse(time.RFC3339, bedtimeValue)
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.New("bedtime must be RFC 3339")
+	}
+	wakeTime, err := time.Parse(time.RFC3339, wakeTimeValue)
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.New("wake_time must be RFC 3339")
+	}
+
+	if !wakeTime.After(bedtime) {
+		return time.Time{}, time.Time{}, errors.New("wake_time must be after bedtime")
+	}
+	if wakeTime.Sub(bedtime) > maxSleepSessionHours*time.Hour {
+		return time.Time{}, time.Time{}, fmt.Errorf("a session cannot be longer than %d hours", maxSleepSessionHours)
+	}
+	// Allow a little clock skew between client and server
+	if wakeTime.After(time.Now().Add(5 * time.Minute)) {
+		return time.Time{}, time.Time{}, errors.New("wake_time must not be in the future")
+	}
+	return bedtime, wakeTime, nil
+}
+
+// loadSleepStats summarises the user's sleep over the last `days` days,
+// including today
+func loadSleepStats(userID uint, days int) models.SleepStats {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	from := today.AddDate(0, 0, -(days - 1))
+
+	var sessions []models.SleepSession
+	database.DB.Where("user_id = ? AND wake_time >= ?", userID, from).
+		Order("wake_time asc").Find(&sessions)
+
+	stats := summarizeSleep(sessions, sleepTargetHours(userID))
+	stats.From = from.Format("2006-01-02")
+	stats.To = today.Format("2006-01-02")
+	stats.Days = days
+	return stats
+}
+
+// sleepTargetHours uses the user's open sleep goal when it looks like a
+// nightly target, otherwise the configured default
+func sleepTargetHours(userID uint) float64 {
+	var goal models.Goal
+	result := database.DB.Where("user_id = ? AND type = ? AND is_completed = ?", userID, models.GoalTypeSleep, false).
+		Order("created_at desc").First(&goal)
+	if result.Error == nil && goal.Target >= 4 && goal.Target <= 14 {
+		return goal.Target
+	}
+	return config.AppConfig.SleepTargetHours
+}
+
+// summarizeSleep groups sessions into nights by the date they end on in
+// the user's own clock. Duration and debt use every session of a night, while bedtime and
+// wake time consistency only use the longest one so naps don't skew it.
+func summarizeSleep(sessions []models.SleepSession, targetHours float64) models.SleepStats {
+	stats := models.SleepStats{TargetHours: targetHours}
+	if len(sessions) == 0 {
+		return stats
+	}
+
+	type night struct {
+		minutes    int
+		awakenings int
+		main       models.SleepSession
+	}
+	nights := make(map[string]*night)
+	var qualitySum int
+	for _, s := range sessions {
+		key := s.Local(s.WakeTime).Format("2006-01-02")
+		n, ok := nights[key]
+		if !ok {
+			n = &night{main: s}
+			nights[key] = n
+		}
+		n.minutes += s.DurationMinutes
+		n.awakenings += s.Awakenings
+		if s.DurationMinutes > n.main.DurationMinutes {
+			n.main = s
+		}
+		qualitySum += s.Quality
+	}
+
+	keys := make([]string, 0, len(nights))
+	for k := range nights {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var totalMinutes, totalAwakenings int
+	var debt float64
+	bedtimes := make([]float64, 0, len(keys))
+	wakeTimes := make([]float64, 0, len(keys))
+	stats.ShortestNightHours = math.MaxFloat64
+	for _, k := range keys {
+		n := nights[k]
+		hours := float64(n.minutes) / 60
+
+		totalMinutes += n.minutes
+		totalAwakenings += n.awakenings
+		debt += targetHours - hours
+		stats.ShortestNightHours = math.Min(stats.ShortestNightHours, hours)
+		stats.LongestNightHours = math.Max(stats.LongestNightHours, hours)
+
+		bedtimes = append(bedtimes, minutesFromNoon(n.main.Local(n.main.Bedtime)))
+		wakeTimes = append(wakeTimes, minutesFromNoon(n.main.Local(n.main.WakeTime)))
+	}
+
+	count := float64(len(keys))
+	stats.NightsLogged = len(keys)
+	stats.AverageHours = round1(float64(totalMinutes) / 60 / count)
+	stats.AverageQuality = round1(float64(qualitySum) / float64(len(sessions)))
+	stats.AverageAwakenings = round1(float64(totalAwakenings) / count)
+	stats.SleepDebtHours = round1(math.Max(0, debt))
+	stats.ShortestNightHours = round1(stats.ShortestNightHours)
+	stats.LongestNightHours = round1(stats.LongestNightHours)
+
+	bedMean, bedSD := meanStdDev(bedtimes)
+	wakeMean, wakeSD := meanStdDev(wakeTimes)
+	stats.BedtimeStdDevMin = round1(bedSD)
+	stats.WakeTimeStdDevMin = round1(wakeSD)
+	stats.AverageBedtime = clockFromNoon(bedMean)
+	stats.AverageWakeTime = clockFromNoon(wakeMean)
+
+	// One night says nothing about regularity. Otherwise a two hour
+	// average deviation between bedtime and wake time scores zero.
+	if stats.NightsLogged >= 2 {
+		spread := (bedSD + wakeSD) / 2
+		stats.ConsistencyScore = int(math.Round(math.Max(0, 100-spread*100/120)))
+	}
+
+	return stats
+}
+
+// minutesFromNoon measures clock time from noon so bedtimes either side
+// of midnight stay close together (23:00 is 660, 01:00 is 780)
+func minutesFromNoon(t time.Time) float64 {
+	minutes := t.Hour()*60 + t.Minute() - 12*60
+	if minutes < 0 {
+		minutes += 24 * 60
+	}
+	return float64(minutes)
+}
+
+func clockFromNoon(minutes float64) string {
+	m := (int(math.Round(minutes)) + 12*60) % (24 * 60)
+	return fmt.Sprintf("%02d:%02d", m/60, m%60)
+}
+
+func meanStdDev(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+
+	var variance float64
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(variance / float64(len(values)))
+}
+
+func round1(v float64) float64 {
+	return math.Round(v*10) / 10
+}
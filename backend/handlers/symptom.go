@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"net/http"
+	"strconv"
 	"time"
 
 	"health-tracker/database"
@@ -44,13 +45,23 @@ func LogSymptom(c *gin.Context) {
 		return
 	}
 
+	loggedAt := time.Now()
+	if req.LoggedAt != "" {
+		t, err := parseRecordDate(req.LoggedAt)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid logged_at: "+err.Error())
+			return
+		}
+		loggedAt = t
+	}
+
 	symptom := models.Symptom{
 		UserID:      userID,
 		SymptomType: req.SymptomType,
 		SymptomName: req.SymptomName,
 		Severity:    req.Severity,
 		Notes:       req.Notes,
-		LoggedAt:    time.Now(),
+		LoggedAt:    loggedAt,
 	}
 
 	if result := database.DB.Create(&symptom); result.Error != nil {
@@ -75,13 +86,22 @@ func LogMultipleSymptoms(c *gin.Context) {
 	now := time.Now()
 
 	for i, req := range requests {
+		loggedAt := now
+		if req.LoggedAt != "" {
+			t, err := parseRecordDate(req.LoggedAt)
+			if err != nil {
+				utils.ErrorResponse(c, http.StatusBadRequest, "Invalid logged_at: "+err.Error())
+				return
+			}
+			loggedAt = t
+		}
 		symptoms[i] = models.Symptom{
 			UserID:      userID,
 			SymptomType: req.SymptomType,
 			SymptomName: req.SymptomName,
 			Severity:    req.Severity,
 			Notes:       req.Notes,
-			LoggedAt:    now,
+			LoggedAt:    loggedAt,
 		}
 	}
 
@@ -93,12 +113,44 @@ func LogMultipleSymptoms(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusCreated, "Symptoms logged successfully", symptoms)
 }
 
-// GetSymptomHistory returns user's symptom history
+// GetSymptomHistory returns user's symptom history, newest first.
+// Filters: from and to (YYYY-MM-DD) with page and limit.
 func GetSymptomHistory(c *gin.Context) {
 	userID := c.GetUint("userID")
 
+	query := database.DB.Model(&models.Symptom{}).Where("user_id = ?", userID)
+
+	if from := c.Query("from"); from != "" {
+		t, err := time.ParseInLocation("2006-01-02", from, time.Local)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid from date, use YYYY-MM-DD")
+			return
+		}
+		query = query.Where("logged_at >= ?", t)
+	}
+	if to := c.Query("to"); to != "" {
+		t, err := time.ParseInLocation("2006-01-02", to, time.Local)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid to date, use YYYY-MM-DD")
+			return
+		}
+		query = query.Where("logged_at < ?", t.AddDate(0, 0, 1))
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 200 {
+		limit = 50
+	}
+
+	var total int64
+	query.Count(&total)
+
 	var symptoms []models.Symptom
-	database.DB.Where("user_id = ?", userID).Order("logged_at desc").Limit(50).Find(&symptoms)
+	query.Order("logged_at desc, id desc").Offset((page - 1) * limit).Limit(limit).Find(&symptoms)
 
 	// Group by date
 	grouped := make(map[string][]models.Symptom)
@@ -110,9 +162,95 @@ func GetSymptomHistory(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, "Symptom history retrieved", gin.H{
 		"symptoms": symptoms,
 		"grouped":  grouped,
+		"total":    total,
+		"page":     page,
+		"limit":    limit,
 	})
 }
 
+// UpdateSymptom edits a logged symptom
+func UpdateSymptom(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var symptom models.Symptom
+	if result := database.DB.Where("id = ? AND user_id = ?", c.Param("id"), userID).First(&symptom); result.Error != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Symptom not found")
+		return
+	}
+
+	var req models.SymptomUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	if req.SymptomType != nil || req.SymptomName != nil {
+		if symptom.EpisodeID != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "This symptom is part of an episode, change its name and type on the episode")
+			return
+		}
+		if req.SymptomType != nil {
+			symptom.SymptomType = *req.SymptomType
+		}
+		if req.SymptomName != nil {
+			symptom.SymptomName = *req.SymptomName
+		}
+		if symptom.SymptomType == "" || symptom.SymptomName == "" {
+			utils.ErrorResponse(c, http.StatusBadRequest, "symptom_type and symptom_name must not be empty")
+			return
+		}
+	}
+	if req.Severity != nil {
+		symptom.Severity = *req.Severity
+	}
+	if req.Notes != nil {
+		symptom.Notes = *req.Notes
+	}
+	if req.LoggedAt != "" {
+		t, err := parseRecordDate(req.LoggedAt)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid logged_at: "+err.Error())
+			return
+		}
+		symptom.LoggedAt = t
+	}
+
+	// Readings must stay within their episode
+	if symptom.EpisodeID != nil {
+		var episode models.SymptomEpisode
+		if database.DB.First(&episode, *symptom.EpisodeID).Error == nil {
+			if symptom.LoggedAt.Before(episode.StartedAt) || (episode.EndedAt != nil && symptom.LoggedAt.After(*episode.EndedAt)) {
+				utils.ErrorResponse(c, http.StatusBadRequest, "logged_at must be within the episode")
+				return
+			}
+		}
+	}
+
+	if result := database.DB.Save(&symptom); result.Error != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update symptom")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Symptom updated", symptom)
+}
+
+// DeleteSymptom removes a logged symptom
+func DeleteSymptom(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	result := database.DB.Where("id = ? AND user_id = ?", c.Param("id"), userID).Delete(&models.Symptom{})
+	if result.Error != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to delete symptom")
+		return
+	}
+	if result.RowsAffected == 0 {
+		utils.ErrorResponse(c, http.StatusNotFound, "Symptom not found")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Symptom deleted", nil)
+}
+
 // GetSymptomStats returns symptom statistics
 func GetSymptomStats(c *gin.Context) {
 	userID := c.GetUint("userID")
@@ -0,0 +1,397 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"health-tracker/database"
+	"health-tracker/models"
+	"health-tracker/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// CreateSymptomEpisode starts a symptom episode with its first severity
+// reading
+func CreateSymptomEpisode(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var req models.SymptomEpisodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	startedAt := time.Now()
+	if req.StartedAt != "" {
+		t, err := parseRecordDate(req.StartedAt)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid started_at: "+err.Error())
+			return
+		}
+		startedAt = t
+	}
+
+	episode := models.SymptomEpisode{
+		UserID:       userID,
+		SymptomType:  req.SymptomType,
+		SymptomName:  req.SymptomName,
+		BodyLocation: req.BodyLocation,
+		Notes:        req.Notes,
+		StartedAt:    startedAt,
+	}
+	episode.SetTriggers(req.Triggers)
+
+	var reading models.Symptom
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&episode).Error; err != nil {
+			return err
+		}
+		reading = models.Symptom{
+			UserID:      userID,
+			EpisodeID:   &episode.ID,
+			SymptomType: episode.SymptomType,
+			SymptomName: episode.SymptomName,
+			Severity:    req.Severity,
+			Notes:       req.Notes,
+			LoggedAt:    startedAt,
+		}
+		return tx.Create(&reading).Error
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to start symptom episode")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Symptom episode started", episodeDetail(episode))
+}
+
+// GetSymptomEpisodes lists the user's episodes, most recently started
+// first. Filters: status (active, resolved or all; default all), from and
+// to (YYYY-MM-DD, episodes overlapping the range) with page and limit.
+func GetSymptomEpisodes(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	query := database.DB.Model(&models.SymptomEpisode{}).Where("user_id = ?", userID)
+
+	switch c.DefaultQuery("status", "all") {
+	case models.EpisodeActive:
+		query = query.Where("ended_at IS NULL")
+	case models.EpisodeResolved:
+		query = query.Where("ended_at IS NOT NULL")
+	case "all":
+	default:
+		utils.ErrorResponse(c, http.StatusBadRequest, "status must be active, resolved or all")
+		return
+	}
+
+	if from := c.Query("from"); from != "" {
+		t, err := time.ParseInLocation("2006-01-02", from, time.Local)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid from date, use YYYY-MM-DD")
+			return
+		}
+		query = query.Where("ended_at IS NULL OR ended_at >= ?", t)
+	}
+	if to := c.Query("to"); to != "" {
+		t, err := time.ParseInLocation("2006-01-02", to, time.Local)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid to date, use YYYY-MM-DD")
+			return
+		}
+		query = query.Where("started_at < ?", t.AddDate(0, 0, 1))
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "30"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 200 {
+		limit = 30
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var episodes []models.SymptomEpisode
+	query.Order("started_at desc, id desc").Offset((page - 1) * limit).Limit(limit).Find(&episodes)
+
+	ids := make([]uint, len(episodes))
+	for i, e := range episodes {
+		ids[i] = e.ID
+	}
+	readings := loadEpisodeReadings(ids)
+
+	now := time.Now()
+	responses := make([]models.SymptomEpisodeResponse, len(episodes))
+	for i, e := range episodes {
+		responses[i] = e.ToResponse(readings[e.ID], now)
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Symptom episodes retrieved", gin.H{
+		"episodes": responses,
+		"total":    total,
+		"page":     page,
+		"limit":    limit,
+	})
+}
+
+// GetSymptomEpisode returns one episode with all its readings
+func GetSymptomEpisode(c *gin.Context) {
+	episode, ok := findSymptomEpisode(c)
+	if !ok {
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Symptom episode retrieved", episodeDetail(episode))
+}
+
+// UpdateSymptomEpisode edits an episode. Name and type changes are copied
+// to its readings.
+func UpdateSymptomEpisode(c *gin.Context) {
+	episode, ok := findSymptomEpisode(c)
+	if !ok {
+		return
+	}
+
+	var req models.SymptomEpisodeUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	if req.SymptomType != nil {
+		episode.SymptomType = *req.SymptomType
+	}
+	if req.SymptomName != nil {
+		if *req.SymptomName == "" {
+			utils.ErrorResponse(c, http.StatusBadRequest, "symptom_name must not be empty")
+			return
+		}
+		episode.SymptomName = *req.SymptomName
+	}
+	if req.BodyLocation != nil {
+		episode.BodyLocation = *req.BodyLocation
+	}
+	if req.Triggers != nil {
+		episode.SetTriggers(req.Triggers)
+	}
+	if req.Notes != nil {
+		episode.Notes = *req.Notes
+	}
+	if req.ResolutionNotes != nil {
+		episode.ResolutionNotes = *req.ResolutionNotes
+	}
+	if req.StartedAt != "" {
+		t, err := parseRecordDate(req.StartedAt)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid started_at: "+err.Error())
+			return
+		}
+		episode.StartedAt = t
+	}
+	if req.Reopen {
+		episode.EndedAt = nil
+	} else if req.EndedAt != "" {
+		t, err := parseRecordDate(req.EndedAt)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ended_at: "+err.Error())
+			return
+		}
+		episode.EndedAt = &t
+	}
+
+	if err := checkEpisodeBounds(episode); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&episode).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Symptom{}).Where("episode_id = ?", episode.ID).Updates(map[string]interface{}{
+			"symptom_type": episode.SymptomType,
+			"symptom_name": episode.SymptomName,
+		}).Error
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update symptom episode")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Symptom episode updated", episodeDetail(episode))
+}
+
+// ResolveSymptomEpisode marks an episode as over
+func ResolveSymptomEpisode(c *gin.Context) {
+	episode, ok := findSymptomEpisode(c)
+	if !ok {
+		return
+	}
+
+	var req models.SymptomResolveRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+	if episode.EndedAt != nil {
+		utils.ErrorResponse(c, http.StatusConflict, "Symptom episode is already resolved")
+		return
+	}
+
+	endedAt := time.Now()
+	if req.EndedAt != "" {
+		t, err := parseRecordDate(req.EndedAt)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ended_at: "+err.Error())
+			return
+		}
+		endedAt = t
+	}
+	episode.EndedAt = &endedAt
+	if req.ResolutionNotes != "" {
+		episode.ResolutionNotes = req.ResolutionNotes
+	}
+
+	if err := checkEpisodeBounds(episode); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if result := database.DB.Save(&episode); result.Error != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to resolve symptom episode")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Symptom episode resolved", episodeDetail(episode))
+}
+
+// DeleteSymptomEpisode removes an episode and its readings
+func DeleteSymptomEpisode(c *gin.Context) {
+	episode, ok := findSymptomEpisode(c)
+	if !ok {
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("episode_id = ?", episode.ID).Delete(&models.Symptom{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&episode).Error
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to delete symptom episode")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Symptom episode deleted", nil)
+}
+
+// AddSymptomReading records the current severity of an open episode
+func AddSymptomReading(c *gin.Context) {
+	episode, ok := findSymptomEpisode(c)
+	if !ok {
+		return
+	}
+
+	var req models.SymptomReadingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+	if episode.EndedAt != nil {
+		utils.ErrorResponse(c, http.StatusConflict, "Symptom episode is resolved, reopen it to add readings")
+		return
+	}
+
+	loggedAt := time.Now()
+	if req.LoggedAt != "" {
+		t, err := parseRecordDate(req.LoggedAt)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid logged_at: "+err.Error())
+			return
+		}
+		loggedAt = t
+	}
+	if loggedAt.Before(episode.StartedAt) {
+		utils.ErrorResponse(c, http.StatusBadRequest, "logged_at must not be before the episode started")
+		return
+	}
+
+	reading := models.Symptom{
+		UserID:      episode.UserID,
+		EpisodeID:   &episode.ID,
+		SymptomType: episode.SymptomType,
+		SymptomName: episode.SymptomName,
+		Severity:    req.Severity,
+		Notes:       req.Notes,
+		LoggedAt:    loggedAt,
+	}
+	if result := database.DB.Create(&reading); result.Error != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to log symptom reading")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Symptom reading logged", reading)
+}
+
+// findSymptomEpisode loads the episode in the :id parameter, responding
+// with 404 when the user has no such episode
+func findSymptomEpisode(c *gin.Context) (models.SymptomEpisode, bool) {
+	var episode models.SymptomEpisode
+	if result := database.DB.Where("id = ? AND user_id = ?", c.Param("id"), c.GetUint("userID")).First(&episode); result.Error != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Symptom episode not found")
+		return episode, false
+	}
+	return episode, true
+}
+
+// checkEpisodeBounds makes sure the episode still covers all its readings
+func checkEpisodeBounds(episode models.SymptomEpisode) error {
+	if episode.EndedAt != nil && episode.EndedAt.Before(episode.StartedAt) {
+		return errors.New("ended_at must not be before started_at")
+	}
+
+	var first, last models.Symptom
+	if database.DB.Where("episode_id = ?", episode.ID).Order("logged_at asc").First(&first).Error != nil {
+		return nil
+	}
+	database.DB.Where("episode_id = ?", episode.ID).Order("logged_at desc").First(&last)
+
+	if first.LoggedAt.Before(episode.StartedAt) {
+		return errors.New("started_at must not be after the first reading")
+	}
+	if episode.EndedAt != nil && last.LoggedAt.After(*episode.EndedAt) {
+		return errors.New("ended_at must not be before the last reading")
+	}
+	return nil
+}
+
+// loadEpisodeReadings returns the readings of each episode, oldest first
+func loadEpisodeReadings(episodeIDs []uint) map[uint][]models.Symptom {
+	byEpisode := make(map[uint][]models.Symptom)
+	if len(episodeIDs) == 0 {
+		return byEpisode
+	}
+
+	var readings []models.Symptom
+	database.DB.Where("episode_id IN ?", episodeIDs).Order("logged_at asc, id asc").Find(&readings)
+	for _, r := range readings {
+		byEpisode[*r.EpisodeID] = append(byEpisode[*r.EpisodeID], r)
+	}
+	return byEpisode
+}
+
+// episodeDetail is the episode response with its readings included
+func episodeDetail(episode models.SymptomEpisode) models.SymptomEpisodeResponse {
+	readings := loadEpisodeReadings([]uint{episode.ID})[episode.ID]
+	resp := episode.ToResponse(readings, time.Now())
+	resp.Readings = readings
+	return resp
+}
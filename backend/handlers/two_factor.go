@@ -0,0 +1,301 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"health-tracker/audit"
+	"health-tracker/config"
+	"health-tracker/database"
+	"health-tracker/models"
+	"health-tracker/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// SetupTwoFactor generates a TOTP secret for the user to scan.
+// Two-factor stays disabled until EnableTwoFactor confirms a code.
+func SetupTwoFactor(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var user models.User
+	if result := database.DB.First(&user, userID); result.Error != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "User not found")
+		return
+	}
+
+	if user.TwoFactorEnabled {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Two-factor authentication is already enabled")
+		return
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to generate secret")
+		return
+	}
+
+	database.DB.Model(&user).Update("totp_pending_secret", secret)
+
+	utils.SuccessResponse(c, http.StatusOK, "Scan the QR code with your authenticator app", models.TwoFactorSetupResponse{
+		Secret:          secret,
+		ProvisioningURI: utils.TOTPProvisioningURI(secret, config.AppConfig.TOTPIssuer, user.Email),
+	})
+}
+
+// EnableTwoFactor confirms the pending secret with a code and returns
+// the initial set of recovery codes
+func EnableTwoFactor(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var req models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	var user models.User
+	if result := database.DB.First(&user, userID); result.Error != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "User not found")
+		return
+	}
+
+	if user.TwoFactorEnabled {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Two-factor authentication is already enabled")
+		return
+	}
+	if user.TOTPPendingSecret == "" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Start two-factor setup first")
+		return
+	}
+
+	step, ok := utils.ValidateTOTP(user.TOTPPendingSecret, req.Code, time.Now())
+	if !ok {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid authentication code")
+		return
+	}
+
+	var codes []string
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Updates(map[string]interface{}{
+			"two_factor_enabled":  true,
+			"totp_secret":         user.TOTPPendingSecret,
+			"totp_pending_secret": "",
+			"totp_last_used_step": step,
+		}).Error; err != nil {
+			return err
+		}
+
+		var err error
+		codes, err = replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to enable two-factor authentication")
+		return
+	}
+
+	audit.Record(c, audit.Entry{Action: models.AuditTwoFactorOn, TargetUserID: userID})
+
+	utils.SuccessResponse(c, http.StatusOK, "Two-factor authentication enabled. Store these recovery codes safely.", models.RecoveryCodesResponse{
+		RecoveryCodes: codes,
+	})
+}
+
+// DisableTwoFactor turns off two-factor after checking password and a code
+func DisableTwoFactor(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var req models.TwoFactorDisableRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	var user models.User
+	if result := database.DB.First(&user, userID); result.Error != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "User not found")
+		return
+	}
+
+	if !user.TwoFactorEnabled {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Two-factor authentication is not enabled")
+		return
+	}
+
+	if !utils.CheckPassword(req.Password, user.Password) {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid password")
+		return
+	}
+
+	if !verifySecondFactor(&user, req.Code) {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid authentication code")
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Updates(map[string]interface{}{
+			"two_factor_enabled":  false,
+			"totp_secret":         "",
+			"totp_pending_secret": "",
+			"totp_last_used_step": 0,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", user.ID).Delete(&models.RecoveryCode{}).Error
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to disable two-factor authentication")
+		return
+	}
+
+	audit.Record(c, audit.Entry{Action: models.AuditTwoFactorOff, TargetUserID: userID})
+
+	utils.SuccessResponse(c, http.StatusOK, "Two-factor authentication disabled", nil)
+}
+
+// RegenerateRecoveryCodes invalidates all recovery codes and issues new ones
+func RegenerateRecoveryCodes(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var req models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	var user models.User
+	if result := database.DB.First(&user, userID); result.Error != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "User not found")
+		return
+	}
+
+	if !user.TwoFactorEnabled {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Two-factor authentication is not enabled")
+		return
+	}
+
+	// Only an authenticator code proves possession here, not a recovery code
+	if !verifyTOTP(&user, req.Code) {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid authentication code")
+		return
+	}
+
+	var codes []string
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		codes, err = replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to generate recovery codes")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Recovery codes regenerated", models.RecoveryCodesResponse{
+		RecoveryCodes: codes,
+	})
+}
+
+// VerifyTwoFactorLogin completes a login that returned a two-factor challenge
+func VerifyTwoFactorLogin(c *gin.Context) {
+	var req models.TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	claims, err := utils.ValidateTwoFactorToken(req.TwoFactorToken)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid or expired two-factor token, please login again")
+		return
+	}
+
+	var user models.User
+	if result := database.DB.First(&user, claims.UserID); result.Error != nil || !user.TwoFactorEnabled {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid or expired two-factor token, please login again")
+		return
+	}
+
+	if rejectLockedAccount(c, &user) {
+		return
+	}
+
+	if !verifySecondFactor(&user, req.Code) {
+		registerLoginFailure(c, &user, models.LoginFailureBadTwoFactor)
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid authentication code")
+		return
+	}
+
+	response, err := startSession(c, user)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to generate token")
+		return
+	}
+
+	registerLoginSuccess(c, &user)
+
+	utils.SuccessResponse(c, http.StatusOK, "Login successful", response)
+}
+
+// verifySecondFactor accepts either a TOTP code or an unused recovery code
+func verifySecondFactor(user *models.User, code string) bool {
+	return verifyTOTP(user, code) || useRecoveryCode(user.ID, code)
+}
+
+// verifyTOTP checks a TOTP code and records its time step so the same
+// code cannot be used twice
+func verifyTOTP(user *models.User, code string) bool {
+	step, ok := utils.ValidateTOTP(user.TOTPSecret, code, time.Now())
+	if !ok || step <= user.TOTPLastUsedStep {
+		return false
+	}
+
+	result := database.DB.Model(&models.User{}).
+		Where("id = ? AND totp_last_used_step < ?", user.ID, step).
+		Update("totp_last_used_step", step)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false
+	}
+
+	user.TOTPLastUsedStep = step
+	return true
+}
+
+func useRecoveryCode(userID uint, code string) bool {
+	hash := utils.HashToken(utils.NormalizeRecoveryCode(code))
+
+	result := database.DB.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hash).
+		Update("used_at", time.Now())
+
+	return result.Error == nil && result.RowsAffected > 0
+}
+
+// replaceRecoveryCodes deletes the user's recovery codes and creates a new set
+func replaceRecoveryCodes(tx *gorm.DB, userID uint) ([]string, error) {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, models.RecoveryCodeCount)
+	records := make([]models.RecoveryCode, models.RecoveryCodeCount)
+	for i := range codes {
+		code, err := utils.GenerateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes[i] = code
+		records[i] = models.RecoveryCode{
+			UserID:   userID,
+			CodeHash: utils.HashToken(utils.NormalizeRecoveryCode(code)),
+		}
+	}
+
+	if err := tx.Create(&records).Error; err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"health-tracker/config"
+	"health-tracker/database"
+	"health-tracker/mailer"
+	"health-tracker/models"
+	"health-tracker/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// sendVerificationEmail issues a fresh verification token and emails it.
+// Older unused tokens for the user stop working.
+func sendVerificationEmail(user models.User) error {
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return err
+	}
+
+	database.DB.Where("user_id = ? AND used_at IS NULL", user.ID).Delete(&models.EmailVerificationToken{})
+
+	expiry := time.Duration(config.AppConfig.EmailVerificationExpiryHours) * time.Hour
+	record := models.EmailVerificationToken{
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().Add(expiry),
+	}
+	if err := database.DB.Create(&record).Error; err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/verify-email?token=%s", config.AppConfig.FrontendURL, url.QueryEscape(token))
+	return mailer.AppMailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Verifikasi Email Health Tracker",
+		Body: fmt.Sprintf("Halo %s,\n\nTerima kasih telah mendaftar di Health Tracker.\n"+
+			"Buka link berikut untuk memverifikasi email Anda (berlaku %d jam):\n\n%s\n\n"+
+			"Jika Anda tidak mendaftar, abaikan email ini.",
+			user.Name, config.AppConfig.EmailVerificationExpiryHours, link),
+	})
+}
+
+// VerifyEmail marks the user's email as verified using the emailed token
+func VerifyEmail(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Token is required")
+		return
+	}
+
+	var record models.EmailVerificationToken
+	if result := database.DB.Where("token_hash = ? AND used_at IS NULL AND expires_at > ?",
+		utils.HashToken(token), time.Now()).First(&record); result.Error != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Token verifikasi tidak valid atau sudah kedaluwarsa")
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.EmailVerificationToken{}).
+			Where("id = ? AND used_at IS NULL", record.ID).
+			Update("used_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errTokenAlreadyUsed
+		}
+
+		return tx.Model(&models.User{}).Where("id = ?", record.UserID).
+			Update("email_verified", true).Error
+	})
+	if errors.Is(err, errTokenAlreadyUsed) {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Token verifikasi tidak valid atau sudah kedaluwarsa")
+		return
+	}
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to verify email")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Email berhasil diverifikasi", nil)
+}
+
+// ResendVerification sends a new verification email to the current user
+func ResendVerification(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var user models.User
+	if result := database.DB.First(&user, userID); result.Error != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "User not found")
+		return
+	}
+
+	if user.EmailVerified {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Email sudah terverifikasi")
+		return
+	}
+
+	if err := sendVerificationEmail(user); err != nil {
+		log.Printf("Failed to send verification email to user %d: %v", user.ID, err)
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to send verification email")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Email verifikasi telah dikirim", nil)
+}
+
+// ResendVerificationByEmail sends a new verification email for a signed-out user.
+// The response is the same whether or not the email is registered.
+func ResendVerificationByEmail(c *gin.Context) {
+	var req models.ResendVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	const genericMessage = "Jika email terdaftar dan belum terverifikasi, email verifikasi telah dikirim"
+
+	var user models.User
+	if result := database.DB.Where("email = ?", req.Email).First(&user); result.Error != nil || user.EmailVerified {
+		utils.SuccessResponse(c, http.StatusOK, genericMessage, nil)
+		return
+	}
+
+	if err := sendVerificationEmail(user); err != nil {
+		log.Printf("Failed to send verification email to user %d: %v", user.ID, err)
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, genericMessage, nil)
+}
@@ -0,0 +1,248 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"health-tracker/database"
+	"health-tracker/models"
+	"health-tracker/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetVitalTypes returns the supported vital sign types with their units,
+// valid bounds and normal ranges
+func GetVitalTypes(c *gin.Context) {
+	utils.SuccessResponse(c, http.StatusOK, "Vital sign types retrieved", models.VitalTypes)
+}
+
+// CreateVitalSign records a vital sign measurement
+func CreateVitalSign(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var req models.VitalSignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	vital := models.VitalSign{UserID: userID, MeasuredAt: time.Now()}
+	if !applyVitalSignRequest(c, &vital, req) {
+		return
+	}
+
+	if result := database.DB.Create(&vital); result.Error != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to save vital sign")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Vital sign recorded", vital)
+}
+
+// GetVitalSigns lists measurements, newest first. Filters: type,
+// out_of_range=true, from and to (YYYY-MM-DD).
+func GetVitalSigns(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	query := database.DB.Model(&models.VitalSign{}).Where("user_id = ?", userID)
+
+	if vitalType := c.Query("type"); vitalType != "" {
+		if _, ok := models.GetVitalType(vitalType); !ok {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Unknown vital sign type")
+			return
+		}
+		query = query.Where("type = ?", vitalType)
+	}
+	if c.Query("out_of_range") == "true" {
+		query = query.Where("out_of_range = ?", true)
+	}
+	if from := c.Query("from"); from != "" {
+		t, err := time.ParseInLocation("2006-01-02", from, time.Local)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid from date, use YYYY-MM-DD")
+			return
+		}
+		query = query.Where("measured_at >= ?", t)
+	}
+	if to := c.Query("to"); to != "" {
+		t, err := time.ParseInLocation("2006-01-02", to, time.Local)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid to date, use YYYY-MM-DD")
+			return
+		}
+		query = query.Where("measured_at < ?", t.AddDate(0, 0, 1))
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 200 {
+		limit = 50
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var vitals []models.VitalSign
+	query.Order("measured_at desc, id desc").Offset((page - 1) * limit).Limit(limit).Find(&vitals)
+
+	utils.SuccessResponse(c, http.StatusOK, "Vital signs retrieved", gin.H{
+		"vital_signs": vitals,
+		"total":       total,
+		"page":        page,
+		"limit":       limit,
+	})
+}
+
+// GetLatestVitalSigns returns the most recent measurement of each type
+func GetLatestVitalSigns(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	latest := make(map[string]models.VitalSign)
+	for _, vt := range models.VitalTypes {
+		var vital models.VitalSign
+		result := database.DB.Where("user_id = ? AND type = ?", userID, vt.Type).
+			Order("measured_at desc, id desc").First(&vital)
+		if result.Error == nil {
+			latest[vt.Type] = vital
+		}
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Latest vital signs retrieved", latest)
+}
+
+// UpdateVitalSign edits a measurement and reclassifies it
+func UpdateVitalSign(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var vital models.VitalSign
+	if result := database.DB.Where("id = ? AND user_id = ?", c.Param("id"), userID).First(&vital); result.Error != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Vital sign not found")
+		return
+	}
+
+	var req models.VitalSignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	if !applyVitalSignRequest(c, &vital, req) {
+		return
+	}
+
+	if result := database.DB.Save(&vital); result.Error != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update vital sign")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Vital sign updated", vital)
+}
+
+// DeleteVitalSign removes a measurement
+func DeleteVitalSign(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	result := database.DB.Where("id = ? AND user_id = ?", c.Param("id"), userID).Delete(&models.VitalSign{})
+	if result.Error != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to delete vital sign")
+		return
+	}
+	if result.RowsAffected == 0 {
+		utils.ErrorResponse(c, http.StatusNotFound, "Vital sign not found")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Vital sign deleted", nil)
+}
+
+// GetVitalSignGraph returns graph data for one vital sign type over the
+// same periods as GetHealthGraph, with the normal range for reference bands
+func GetVitalSignGraph(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	vt, ok := models.GetVitalType(c.Query("type"))
+	if !ok {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Query parameter type must be a known vital sign type")
+		return
+	}
+
+	startDate := graphPeriodStart(c.Param("period"))
+
+	var vitals []models.VitalSign
+	database.DB.Where("user_id = ? AND type = ? AND measured_at >= ?", userID, vt.Type, startDate).
+		Order("measured_at asc").Find(&vitals)
+
+	points := make([]map[string]interface{}, len(vitals))
+	for i, v := range vitals {
+		point := map[string]interface{}{
+			"date":           v.MeasuredAt.Format("2006-01-02"),
+			"measured_at":    v.MeasuredAt,
+			"value":          v.Value,
+			"classification": v.Classification,
+			"out_of_range":   v.OutOfRange,
+		}
+		if v.SecondaryValue != nil {
+			point["secondary_value"] = *v.SecondaryValue
+		}
+		if v.Context != "" {
+			point["context"] = v.Context
+		}
+		points[i] = point
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Graph data retrieved", gin.H{
+		"type":             vt.Type,
+		"unit":             vt.Unit,
+		"normal":           vt.Normal,
+		"secondary_normal": vt.SecondaryNormal,
+		"points":           points,
+	})
+}
+
+// applyVitalSignRequest validates req against the type registry and
+// copies it onto vital along with its classification. It writes the
+// error response and returns false when the request is invalid.
+func applyVitalSignRequest(c *gin.Context, vital *models.VitalSign, req models.VitalSignRequest) bool {
+	vt, ok := models.GetVitalType(req.Type)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Unknown vital sign type")
+		return false
+	}
+
+	if req.MeasuredAt != "" {
+		measuredAt, err := parseRecordDate(req.MeasuredAt)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid measured_at: "+err.Error())
+			return false
+		}
+		vital.MeasuredAt = measuredAt
+	}
+
+	context := req.Context
+	if context == "" {
+		context = vt.DefaultContext
+	}
+
+	vital.Type = vt.Type
+	vital.Value = req.Value
+	vital.SecondaryValue = nil
+	if vt.SecondaryValid != nil {
+		vital.SecondaryValue = req.SecondaryValue
+	}
+	vital.Unit = vt.Unit
+	vital.Context = context
+	vital.Notes = req.Notes
+
+	if err := vt.Validate(*vital); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid vital sign: "+err.Error())
+		return false
+	}
+
+	vital.Classification, vital.OutOfRange = vt.Classify(*vital)
+	return true
+}
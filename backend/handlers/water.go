@@ -1,8 +1,12 @@
 package handlers
 
 import (
+	"errors"
 	"health-tracker/database"
 	"health-tracker/models"
+	"health-tracker/units"
+	"io"
+	"math"
 	"net/http"
 	"time"
 
@@ -26,6 +30,8 @@ func GetWaterIntake(c *gin.Context) {
 			UserID:    userID.(uint),
 			Glasses:   0,
 			Goal:      8,
+			AmountMl:  0,
+			GoalMl:    2000,
 			Date:      today,
 			CreatedAt: time.Now(),
 			UpdatedAt: time.Now(),
@@ -33,17 +39,12 @@ func GetWaterIntake(c *gin.Context) {
 		database.DB.Create(&water)
 	}
 
-	c.JSON(http.StatusOK, models.WaterIntakeResponse{
-		ID:         water.ID,
-		Glasses:    water.Glasses,
-		Goal:       water.Goal,
-		Date:       water.Date,
-		Percentage: water.GetPercentage(),
-		Remaining:  water.GetRemaining(),
-	})
+	c.JSON(http.StatusOK, newWaterResponse(water, loadUnits(userID.(uint)).Volume))
 }
 
-// AddWaterGlass adds a glass of water
+// AddWaterGlass adds a glass of water, or the amount given in the
+// optional body ({"amount": 12, "unit": "fl_oz"}, unit defaults to the
+// user's volume unit)
 func AddWaterGlass(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
@@ -51,6 +52,11 @@ func AddWaterGlass(c *gin.Context) {
 		return
 	}
 
+	ml, ok := waterAmountMl(c, userID.(uint))
+	if !ok {
+		return
+	}
+
 	today := time.Now().Format("2006-01-02")
 	var water models.WaterIntake
 
@@ -58,31 +64,26 @@ func AddWaterGlass(c *gin.Context) {
 		// Create new record for today
 		water = models.WaterIntake{
 			UserID:    userID.(uint),
-			Glasses:   1,
 			Goal:      8,
+			GoalMl:    2000,
 			Date:      today,
 			CreatedAt: time.Now(),
 			UpdatedAt: time.Now(),
 		}
+		water.SetAmountMl(ml)
 		database.DB.Create(&water)
 	} else {
 		// Update existing record
-		water.Glasses++
+		water.SetAmountMl(water.AmountMl + ml)
 		water.UpdatedAt = time.Now()
 		database.DB.Save(&water)
 	}
 
-	c.JSON(http.StatusOK, models.WaterIntakeResponse{
-		ID:         water.ID,
-		Glasses:    water.Glasses,
-		Goal:       water.Goal,
-		Date:       water.Date,
-		Percentage: water.GetPercentage(),
-		Remaining:  water.GetRemaining(),
-	})
+	c.JSON(http.StatusOK, newWaterResponse(water, loadUnits(userID.(uint)).Volume))
 }
 
-// RemoveWaterGlass removes a glass of water
+// RemoveWaterGlass removes a glass of water, or the amount given in the
+// optional body like AddWaterGlass
 func RemoveWaterGlass(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
@@ -90,6 +91,11 @@ func RemoveWaterGlass(c *gin.Context) {
 		return
 	}
 
+	ml, ok := waterAmountMl(c, userID.(uint))
+	if !ok {
+		return
+	}
+
 	today := time.Now().Format("2006-01-02")
 	var water models.WaterIntake
 
@@ -98,20 +104,13 @@ func RemoveWaterGlass(c *gin.Context) {
 		return
 	}
 
-	if water.Glasses > 0 {
-		water.Glasses--
+	if water.AmountMl > 0 {
+		water.SetAmountMl(water.AmountMl - ml)
 		water.UpdatedAt = time.Now()
 		database.DB.Save(&water)
 	}
 
-	c.JSON(http.StatusOK, models.WaterIntakeResponse{
-		ID:         water.ID,
-		Glasses:    water.Glasses,
-		Goal:       water.Goal,
-		Date:       water.Date,
-		Percentage: water.GetPercentage(),
-		Remaining:  water.GetRemaining(),
-	})
+	c.JSON(http.StatusOK, newWaterResponse(water, loadUnits(userID.(uint)).Volume))
 }
 
 // UpdateWaterGoal updates the daily water goal
@@ -122,8 +121,11 @@ func UpdateWaterGoal(c *gin.Context) {
 		return
 	}
 
+	// Goal is in glasses; goal_amount is in unit or the user's volume unit
 	var input struct {
-		Goal int `json:"goal" binding:"required,min=1,max=20"`
+		Goal       int     `json:"goal" binding:"omitempty,min=1,max=20"`
+		GoalAmount float64 `json:"goal_amount" binding:"omitempty,gt=0"`
+		Unit       string  `json:"unit"`
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -131,6 +133,23 @@ func UpdateWaterGoal(c *gin.Context) {
 		return
 	}
 
+	goalMl := float64(input.Goal * units.MlPerGlass)
+	if input.GoalAmount > 0 {
+		unit := input.Unit
+		if unit == "" {
+			unit = loadUnits(userID.(uint)).Volume
+		}
+		var err error
+		if goalMl, err = units.ToMl(input.GoalAmount, unit); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if goalMl < 250 || goalMl > 5000 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Goal must be between 250 ml and 5000 ml (1-20 glasses)"})
+		return
+	}
+
 	today := time.Now().Format("2006-01-02")
 	var water models.WaterIntake
 
@@ -138,26 +157,19 @@ func UpdateWaterGoal(c *gin.Context) {
 		water = models.WaterIntake{
 			UserID:    userID.(uint),
 			Glasses:   0,
-			Goal:      input.Goal,
 			Date:      today,
 			CreatedAt: time.Now(),
 			UpdatedAt: time.Now(),
 		}
+		water.SetGoalMl(int(math.Round(goalMl)))
 		database.DB.Create(&water)
 	} else {
-		water.Goal = input.Goal
+		water.SetGoalMl(int(math.Round(goalMl)))
 		water.UpdatedAt = time.Now()
 		database.DB.Save(&water)
 	}
 
-	c.JSON(http.StatusOK, models.WaterIntakeResponse{
-		ID:         water.ID,
-		Glasses:    water.Glasses,
-		Goal:       water.Goal,
-		Date:       water.Date,
-		Percentage: water.GetPercentage(),
-		Remaining:  water.GetRemaining(),
-	})
+	c.JSON(http.StatusOK, newWaterResponse(water, loadUnits(userID.(uint)).Volume))
 }
 
 // GetWaterHistory returns water intake history for past days
@@ -176,17 +188,54 @@ func GetWaterHistory(c *gin.Context) {
 		Limit(7).
 		Find(&history)
 
+	volumeUnit := loadUnits(userID.(uint)).Volume
 	var response []models.WaterIntakeResponse
 	for _, water := range history {
-		response = append(response, models.WaterIntakeResponse{
-			ID:         water.ID,
-			Glasses:    water.Glasses,
-			Goal:       water.Goal,
-			Date:       water.Date,
-			Percentage: water.GetPercentage(),
-			Remaining:  water.GetRemaining(),
-		})
+		response = append(response, newWaterResponse(water, volumeUnit))
 	}
 
 	c.JSON(http.StatusOK, response)
 }
+
+// waterAmountMl reads the optional {"amount", "unit"} body of the add and
+// remove endpoints. Without a body the amount is one glass.
+func waterAmountMl(c *gin.Context, userID uint) (int, bool) {
+	var input struct {
+		Amount float64 `json:"amount" binding:"omitempty,gt=0,lte=5000"`
+		Unit   string  `json:"unit"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return 0, false
+	}
+	if input.Amount == 0 {
+		return units.MlPerGlass, true
+	}
+
+	unit := input.Unit
+	if unit == "" {
+		unit = loadUnits(userID).Volume
+	}
+	ml, err := units.ToMl(input.Amount, unit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return 0, false
+	}
+	return int(math.Round(ml)), true
+}
+
+// newWaterResponse builds the response with amounts in volumeUnit
+func newWaterResponse(water models.WaterIntake, volumeUnit string) models.WaterIntakeResponse {
+	return models.WaterIntakeResponse{
+		ID:              water.ID,
+		Glasses:         water.Glasses,
+		Goal:            water.Goal,
+		Date:            water.Date,
+		Percentage:      water.GetPercentage(),
+		Remaining:       water.GetRemaining(),
+		Amount:          units.Round(units.FromMl(float64(water.AmountMl), volumeUnit), 1),
+		GoalAmount:      units.Round(units.FromMl(float64(water.GoalMl), volumeUnit), 1),
+		RemainingAmount: units.Round(units.FromMl(float64(water.GetRemainingMl()), volumeUnit), 1),
+		Unit:            volumeUnit,
+	}
+}
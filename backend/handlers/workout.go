@@ -0,0 +1,320 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"health-tracker/database"
+	"health-tracker/models"
+	"health-tracker/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultWorkoutWeightKg is used for calorie estimates until the user
+// has recorded their weight
+const defaultWorkoutWeightKg = 70
+
+// GetWorkoutTypes returns the MET table used for calorie estimates
+func GetWorkoutTypes(c *gin.Context) {
+	utils.SuccessResponse(c, http.StatusOK, "Workout types retrieved", models.WorkoutTypes)
+}
+
+// LogWorkout records a workout and estimates the calories burned
+func LogWorkout(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var req models.WorkoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	workout := models.Workout{UserID: userID, PerformedAt: time.Now()}
+	if !applyWorkoutRequest(c, &workout, req) {
+		return
+	}
+
+	if result := database.DB.Create(&workout); result.Error != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to save workout")
+		return
+	}
+
+	syncActivityLevel(userID)
+
+	utils.SuccessResponse(c, http.StatusCreated, "Workout logged", workout)
+}
+
+// GetWorkouts lists workouts, newest first. Filters: type, from and to
+// (YYYY-MM-DD) with page and limit.
+func GetWorkouts(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	query := database.DB.Model(&models.Workout{}).Where("user_id = ?", userID)
+
+	if workoutType := c.Query("type"); workoutType != "" {
+		query = query.Where("type = ?", workoutType)
+	}
+	if from := c.Query("from"); from != "" {
+		t, err := time.ParseInLocation("2006-01-02", from, time.Local)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid from date, use YYYY-MM-DD")
+			return
+		}
+		query = query.Where("performed_at >= ?", t)
+	}
+	if to := c.Query("to"); to != "" {
+		t, err := time.ParseInLocation("2006-01-02", to, time.Local)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid to date, use YYYY-MM-DD")
+			return
+		}
+		query = query.Where("performed_at < ?", t.AddDate(0, 0, 1))
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "30"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 200 {
+		limit = 30
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var workouts []models.Workout
+	query.Order("performed_at desc, id desc").Offset((page - 1) * limit).Limit(limit).Find(&workouts)
+
+	utils.SuccessResponse(c, http.StatusOK, "Workouts retrieved", gin.H{
+		"workouts": workouts,
+		"total":    total,
+		"page":     page,
+		"limit":    limit,
+	})
+}
+
+// GetWorkoutSummary totals workouts over the last `days` days (default 7)
+// and reports the activity level they imply
+func GetWorkoutSummary(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	days, err := strconv.Atoi(c.DefaultQuery("days", "7"))
+	if err != nil || days < 1 || days > 90 {
+		utils.ErrorResponse(c, http.StatusBadRequest, "days must be between 1 and 90")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Workout summary retrieved", loadWorkoutSummary(userID, days))
+}
+
+// UpdateWorkout edits a workout and recalculates its calories with the
+// weight stored on it, or the current weight if recalculate_weight is set
+func UpdateWorkout(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var workout models.Workout
+	if result := database.DB.Where("id = ? AND user_id = ?", c.Param("id"), userID).First(&workout); result.Error != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Workout not found")
+		return
+	}
+
+	var req models.WorkoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	if !applyWorkoutRequest(c, &workout, req) {
+		return
+	}
+
+	if result := database.DB.Save(&workout); result.Error != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update workout")
+		return
+	}
+
+	syncActivityLevel(userID)
+
+	utils.SuccessResponse(c, http.StatusOK, "Workout updated", workout)
+}
+
+// DeleteWorkout removes a workout
+func DeleteWorkout(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	result := database.DB.Where("id = ? AND user_id = ?", c.Param("id"), userID).Delete(&models.Workout{})
+	if result.Error != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to delete workout")
+		return
+	}
+	if result.RowsAffected == 0 {
+		utils.ErrorResponse(c, http.StatusNotFound, "Workout not found")
+		return
+	}
+
+	syncActivityLevel(userID)
+
+	utils.SuccessResponse(c, http.StatusOK, "Workout deleted", nil)
+}
+
+// applyWorkoutRequest validates req against the MET table and copies it
+// onto workout with a fresh calorie estimate. New workouts take the
+// current weight; existing ones keep theirs unless the request asks for a
+// recalculation. It writes the error response and returns false when the
+// request is invalid.
+func applyWorkoutRequest(c *gin.Context, workout *models.Workout, req models.WorkoutRequest) bool {
+	wt, ok := models.GetWorkoutType(req.Type)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Unknown workout type")
+		return false
+	}
+	if req.DistanceKm != nil && !wt.Distance {
+		utils.ErrorResponse(c, http.StatusBadRequest, "distance_km is not tracked for "+wt.Type)
+		return false
+	}
+
+	if req.PerformedAt != "" {
+		performedAt, err := parseRecordDate(req.PerformedAt)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid performed_at: "+err.Error())
+			return false
+		}
+		workout.PerformedAt = performedAt
+	}
+
+	intensity := req.Intensity
+	if intensity == "" {
+		intensity = models.IntensityModerate
+	}
+
+	workout.Type = wt.Type
+	workout.DurationMinutes = req.DurationMinutes
+	workout.Intensity = intensity
+	workout.DistanceKm = req.DistanceKm
+	workout.Steps = req.Steps
+	workout.Notes = req.Notes
+	workout.MET = wt.EstimateMET(intensity, req.DurationMinutes, req.DistanceKm)
+	if workout.WeightKg <= 0 || req.RecalculateWeight {
+		workout.WeightKg = latestWeightKg(workout.UserID)
+	}
+	workout.CaloriesBurned = round1(models.CaloriesBurned(workout.MET, workout.WeightKg, workout.DurationMinutes))
+	return true
+}
+
+// latestWeightKg returns the weight from the most recent health record,
+// falling back to the profile and then to defaultWorkoutWeightKg
+func latestWeightKg(userID uint) float64 {
+	var latest models.HealthData
+	result := database.DB.Where("user_id = ? AND weight_kg > 0", userID).Order("record_date desc, id desc").First(&latest)
+	if result.Error == nil {
+		return latest.WeightKg
+	}
+
+	var user models.User
+	if database.DB.Select("weight_kg").First(&user, userID).Error == nil && user.WeightKg > 0 {
+		return user.WeightKg
+	}
+	return defaultWorkoutWeightKg
+}
+
+// loadWorkoutSummary totals the user's workouts over the last `days` days,
+// including today
+func loadWorkoutSummary(userID uint, days int) models.WorkoutSummary {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	from := today.AddDate(0, 0, -(days - 1))
+
+	var workouts []models.Workout
+	database.DB.Where("user_id = ? AND performed_at >= ?", userID, from).Find(&workouts)
+
+	summary := models.WorkoutSummary{
+		From: from.Format("2006-01-02"),
+		To:   today.Format("2006-01-02"),
+		Days: days,
+	}
+	activeDays := make(map[string]bool)
+	for _, w := range workouts {
+		summary.Sessions++
+		summary.TotalMinutes += w.DurationMinutes
+		summary.TotalCalories += w.CaloriesBurned
+		summary.METMinutes += w.MET * float64(w.DurationMinutes)
+		if w.DistanceKm != nil {
+			summary.TotalDistance += *w.DistanceKm
+		}
+		if w.Steps != nil {
+			summary.TotalSteps += *w.Steps
+		}
+		activeDays[w.PerformedAt.In(time.Local).Format("2006-01-02")] = true
+	}
+	summary.ActiveDays = len(activeDays)
+	summary.TotalCalories = round1(summary.TotalCalories)
+	summary.TotalDistance = round1(summary.TotalDistance)
+	summary.METMinutes = round1(summary.METMinutes)
+
+	// Levels are defined per week, so scale longer windows down
+	weeklyDays := float64(summary.ActiveDays) * 7 / float64(days)
+	weeklyMETMinutes := summary.METMinutes * 7 / float64(days)
+	if days < 7 {
+		weeklyDays = float64(summary.ActiveDays)
+		weeklyMETMinutes = summary.METMinutes
+	}
+	summary.ActivityLevel = deriveActivityLevel(weeklyDays, weeklyMETMinutes)
+	summary.MeetsWHOTarget = weeklyMETMinutes >= 600
+	return summary
+}
+
+// deriveActivityLevel maps a week of workouts onto the profile levels:
+// 1-2 active days is light, 3-4 moderate and 5 or more active. Meeting
+// the WHO minimum of 600 MET-minutes counts as at least moderate.
+func deriveActivityLevel(activeDays, metMinutes float64) string {
+	level := models.ActivitySedentary
+	switch {
+	case activeDays >= 5:
+		level = models.ActivityActive
+	case activeDays >= 3:
+		level = models.ActivityModerate
+	case activeDays >= 1:
+		level = models.ActivityLight
+	}
+
+	if metMinutes >= 600 && (level == models.ActivitySedentary || level == models.ActivityLight) {
+		level = models.ActivityModerate
+	}
+	return level
+}
+
+// syncActivityLevel switches the user to a workout-derived activity level
+// once they have logged any workout, and back to self-reported when the
+// last workout is deleted
+func syncActivityLevel(userID uint) {
+	var count int64
+	database.DB.Model(&models.Workout{}).Where("user_id = ?", userID).Count(&count)
+
+	if count == 0 {
+		database.DB.Model(&models.User{}).Where("id = ?", userID).
+			Update("activity_level_source", models.ActivitySourceSelfReported)
+		return
+	}
+
+	database.DB.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"activity_level":        loadWorkoutSummary(userID, 7).ActivityLevel,
+		"activity_level_source": models.ActivitySourceWorkouts,
+	})
+}
+
+// refreshActivityLevel recomputes a workout-derived activity level on read,
+// since it decays as workouts fall out of the 7-day window
+func refreshActivityLevel(user *models.User) {
+	if user.ActivityLevelSource != models.ActivitySourceWorkouts {
+		return
+	}
+
+	level := loadWorkoutSummary(user.ID, 7).ActivityLevel
+	if level != user.ActivityLevel {
+		user.ActivityLevel = level
+		database.DB.Model(user).Update("activity_level", level)
+	}
+}
@@ -0,0 +1,38 @@
+package mailer
+
+import (
+	"log"
+
+	"health-tracker/config"
+)
+
+// Message is a single outgoing email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends emails. Implementations must be safe for concurrent use.
+type Mailer interface {
+	Send(msg Message) error
+}
+
+var AppMailer Mailer
+
+// InitMailer selects the mailer implementation from MAIL_DRIVER
+func InitMailer() {
+	cfg := config.AppConfig
+
+	switch cfg.MailDriver {
+	case "smtp":
+		log.Printf("📧 Using SMTP mailer (%s:%s)", cfg.SMTPHost, cfg.SMTPPort)
+		AppMailer = NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.MailFrom)
+	case "file":
+		log.Printf("📧 Using file mailer, writing emails to %s", cfg.MailDir)
+		AppMailer = NewFileMailer(cfg.MailDir, cfg.MailFrom)
+	default:
+		log.Println("📧 Using in-memory mailer, emails will not be delivered")
+		AppMailer = NewMemoryMailer()
+	}
+}
@@ -1,18 +1,43 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"log"
+	"time"
 
+	"health-tracker/account"
+	"health-tracker/anomaly"
+	"health-tracker/audit"
 	"health-tracker/config"
 	"health-tracker/database"
+	"health-tracker/export"
+	"health-tracker/mailer"
+	"health-tracker/models"
+	"health-tracker/oidc"
 	"health-tracker/routes"
+	"health-tracker/utils"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 func main() {
+	makeAdmin := flag.String("make-admin", "", "promote the registered user with this email to admin, then exit")
+	flag.Parse()
+
 	// Load configuration
 	config.LoadConfig()
+	if err := config.AppConfig.Validate(); err != nil {
+		log.Fatal("Invalid configuration: ", err)
+	}
+
+	// Load JWT signing keys
+	keyRetention := time.Duration(config.AppConfig.JWTExpiryHours) * time.Hour
+	keyRotation := time.Duration(config.AppConfig.JWTKeyRotationDays) * 24 * time.Hour
+	if err := utils.InitKeyStore(config.AppConfig.JWTKeyDir, config.AppConfig.JWTAlgorithm, keyRotation, keyRetention); err != nil {
+		log.Fatal("Failed to load JWT signing keys: ", err)
+	}
 
 	// Set Gin mode
 	gin.SetMode(config.AppConfig.GinMode)
@@ -20,6 +45,34 @@ func main() {
 	// Initialize database
 	database.InitDatabase()
 
+	// Bootstrap the first admin: go run main.go -make-admin you@example.com
+	if *makeAdmin != "" {
+		if err := bootstrapAdmin(*makeAdmin); err != nil {
+			log.Fatal("Failed to promote admin: ", err)
+		}
+		log.Printf("👑 %s is now an admin", *makeAdmin)
+		return
+	}
+
+	// Initialize mailer
+	mailer.InitMailer()
+
+	// Register social login providers
+	var oidcProviders []oidc.Config
+	for _, p := range config.AppConfig.OIDCProviders {
+		oidcProviders = append(oidcProviders, oidc.Config(p))
+	}
+	oidc.InitProviders(oidcProviders)
+
+	// Resume background account exports
+	export.StartWorker()
+
+	// Purge accounts whose deletion grace period has ended
+	account.StartPurgeWorker()
+
+	// Check health data for unusual changes
+	anomaly.StartWorker()
+
 	// Create Gin router
 	r := gin.Default()
 
@@ -55,3 +108,29 @@ func main() {
 		log.Fatal("Failed to start server:", err)
 	}
 }
+
+// bootstrapAdmin gives an existing user the admin role and signs them out
+// so their next login carries the new role
+func bootstrapAdmin(email string) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.Where("email = ?", email).First(&user).Error; err != nil {
+			return fmt.Errorf("user %s not found, register first: %w", email, err)
+		}
+
+		oldRole := user.Role
+		if err := tx.Model(&user).Update("role", models.RoleAdmin).Error; err != nil {
+			return err
+		}
+
+		audit.Append(tx, audit.Entry{
+			Action:       models.AuditAdminRoleUpdated,
+			TargetUserID: user.ID,
+			Details:      audit.Details{"old_role": oldRole, "new_role": models.RoleAdmin, "via": "cli"},
+		})
+
+		return tx.Model(&models.Session{}).
+			Where("user_id = ? AND revoked_at IS NULL", user.ID).
+			Updates(map[string]interface{}{"revoked_at": time.Now(), "revoked_reason": models.SessionRevokedRoleChange}).Error
+	})
+}
@@ -3,12 +3,18 @@ package middleware
 import (
 	"net/http"
 	"strings"
+	"time"
 
+	"health-tracker/database"
+	"health-tracker/models"
 	"health-tracker/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
+// AuthMiddleware accepts either "Bearer <jwt>" from a signed-in session or
+// "ApiKey <key>" from a personal API key. Routes must follow it with
+// RequireSession or RequireScope to decide whether API keys are allowed.
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
@@ -19,14 +25,19 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		// Extract token from "Bearer <token>"
+		// Extract token from "Bearer <token>" or "ApiKey <key>"
 		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
+		if len(parts) != 2 || (parts[0] != "Bearer" && parts[0] != "ApiKey") {
 			utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid authorization header format")
 			c.Abort()
 			return
 		}
 
+		if parts[0] == "ApiKey" {
+			authenticateAPIKey(c, parts[1])
+			return
+		}
+
 		tokenString := parts[1]
 		claims, err := utils.ValidateToken(tokenString)
 
@@ -36,14 +47,104 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// Reject tokens whose session was logged out or revoked
+		var session models.Session
+		if result := database.DB.Where("id = ? AND user_id = ?", claims.SessionID, claims.UserID).First(&session); result.Error != nil || !session.IsActive() {
+			utils.ErrorResponse(c, http.StatusUnauthorized, "Session has expired or been revoked")
+			c.Abort()
+			return
+		}
+
 		// Set user info in context
 		c.Set("userID", claims.UserID)
 		c.Set("userEmail", claims.Email)
+		c.Set("userRole", claims.Role)
+		c.Set("sessionID", claims.SessionID)
 
 		c.Next()
 	}
 }
 
+// apiKeyTouchInterval limits how often last_used_at is written
+const apiKeyTouchInterval = time.Minute
+
+func authenticateAPIKey(c *gin.Context, key string) {
+	var apiKey models.APIKey
+	if result := database.DB.Where("key_hash = ?", utils.HashToken(key)).First(&apiKey); result.Error != nil || !apiKey.IsActive() {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid, expired or revoked API key")
+		c.Abort()
+		return
+	}
+
+	// Keys stop working while the account is scheduled for deletion
+	var user models.User
+	if result := database.DB.First(&user, apiKey.UserID); result.Error != nil || user.DeletionScheduledAt != nil {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid, expired or revoked API key")
+		c.Abort()
+		return
+	}
+
+	now := time.Now()
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) > apiKeyTouchInterval {
+		database.DB.Model(&apiKey).Update("last_used_at", now)
+	}
+
+	c.Set("userID", user.ID)
+	c.Set("userEmail", user.Email)
+	c.Set("userRole", user.Role)
+	c.Set("apiKeyID", apiKey.ID)
+	c.Set("apiKeyScopes", apiKey.ScopeList())
+
+	c.Next()
+}
+
+// IsAPIKeyRequest reports whether the request was authenticated with an API key
+func IsAPIKeyRequest(c *gin.Context) bool {
+	_, exists := c.Get("apiKeyID")
+	return exists
+}
+
+// RequireSession rejects API keys, for account and admin routes that need
+// a signed-in user. Must run after AuthMiddleware.
+func RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if IsAPIKeyRequest(c) {
+			utils.ErrorResponse(c, http.StatusForbidden, "API keys cannot access this endpoint")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireScope lets API keys through only if they hold a scope for the
+// resource: "<resource>:read" for GET/HEAD, "<resource>:write" otherwise.
+// A write scope implies read. Session tokens are not restricted.
+// Must run after AuthMiddleware.
+func RequireScope(resource string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !IsAPIKeyRequest(c) {
+			c.Next()
+			return
+		}
+
+		required := resource + ":" + models.ScopeWrite
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			required = resource + ":" + models.ScopeRead
+		}
+
+		for _, scope := range c.GetStringSlice("apiKeyScopes") {
+			if scope == required || scope == resource+":"+models.ScopeWrite {
+				c.Next()
+				return
+			}
+		}
+
+		utils.ErrorResponse(c, http.StatusForbidden, "API key is missing the "+required+" scope")
+		c.Abort()
+	}
+}
+
 func GetUserID(c *gin.Context) uint {
 	userID, exists := c.Get("userID")
 	if !exists {
@@ -52,6 +153,31 @@ func GetUserID(c *gin.Context) uint {
 	return userID.(uint)
 }
 
+// RequireRole allows the request only if the user has one of the given roles.
+// Must run after AuthMiddleware.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := GetUserRole(c)
+		for _, r := range roles {
+			if r == role {
+				c.Next()
+				return
+			}
+		}
+
+		utils.ErrorResponse(c, http.StatusForbidden, "You don't have permission to access this resource")
+		c.Abort()
+	}
+}
+
+func GetUserRole(c *gin.Context) string {
+	role, exists := c.Get("userRole")
+	if !exists {
+		return ""
+	}
+	return role.(string)
+}
+
 func GetUserEmail(c *gin.Context) string {
 	email, exists := c.Get("userEmail")
 	if !exists {
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"net/http"
+
+	"health-tracker/config"
+	"health-tracker/database"
+	"health-tracker/models"
+	"health-tracker/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireVerifiedEmail blocks users with an unverified email when
+// REQUIRE_VERIFIED_EMAIL is enabled. Must run after AuthMiddleware.
+func RequireVerifiedEmail() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !config.AppConfig.RequireVerifiedEmail {
+			c.Next()
+			return
+		}
+
+		var user models.User
+		if result := database.DB.Select("id", "email_verified").First(&user, GetUserID(c)); result.Error != nil || !user.EmailVerified {
+			utils.ErrorResponse(c, http.StatusForbidden, "Please verify your email address first")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
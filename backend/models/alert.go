@@ -0,0 +1,85 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"health-tracker/units"
+)
+
+// Health alert metrics
+const (
+	AlertWeightChange    = "weight_change"    // change over the last 7 days, kg
+	AlertSymptomSeverity = "symptom_severity" // highest severity logged that day, 1-10
+	AlertWaterIntake     = "water_intake"     // total for the day, ml
+)
+
+// Health alert severities
+const (
+	AlertWarning  = "warning"
+	AlertCritical = "critical"
+)
+
+// HealthAlert is an observation that is far outside the user's own
+// baseline. Value and Baseline are in kg, ml or severity points depending
+// on the metric; ZScore is the robust (median/MAD) z-score.
+type HealthAlert struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	UserID         uint       `gorm:"not null;uniqueIndex:idx_health_alert_observation" json:"user_id"`
+	Metric         string     `gorm:"size:30;not null;uniqueIndex:idx_health_alert_observation" json:"metric"`
+	ObservedOn     string     `gorm:"size:10;not null;uniqueIndex:idx_health_alert_observation" json:"observed_on"` // YYYY-MM-DD
+	Direction      string     `gorm:"size:10" json:"direction"`                                                     // increase or decrease
+	Severity       string     `gorm:"size:10" json:"severity"`
+	Value          float64    `json:"value"`
+	Baseline       float64    `json:"baseline"`
+	ZScore         float64    `json:"z_score"`
+	BaselineSize   int        `json:"baseline_size"`
+	NotifiedAt     *time.Time `json:"notified_at"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// HealthAlertView is an alert with its values in the user's units and a
+// readable message
+type HealthAlertView struct {
+	HealthAlert
+	Unit    string `json:"unit"`
+	Message string `json:"message"`
+}
+
+// NewHealthAlertView converts the alert's values to the user's preferred
+// units
+func NewHealthAlertView(a HealthAlert, prefs units.Preferences) HealthAlertView {
+	view := HealthAlertView{HealthAlert: a}
+	switch a.Metric {
+	case AlertWeightChange:
+		view.Unit = prefs.Weight
+		view.Value = units.Round(units.FromKg(a.Value, prefs.Weight), 1)
+		view.Baseline = units.Round(units.FromKg(a.Baseline, prefs.Weight), 1)
+	case AlertWaterIntake:
+		view.Unit = prefs.Volume
+		view.Value = units.Round(units.FromMl(a.Value, prefs.Volume), 1)
+		view.Baseline = units.Round(units.FromMl(a.Baseline, prefs.Volume), 1)
+	}
+	view.ZScore = units.Round(a.ZScore, 1)
+	view.Message = view.describe()
+	return view
+}
+
+func (v HealthAlertView) describe() string {
+	switch v.Metric {
+	case AlertWeightChange:
+		verb := "naik"
+		if v.Value < 0 {
+			verb = "turun"
+		}
+		return fmt.Sprintf("Berat badan %s %.1f %s dalam 7 hari, jauh dari perubahan mingguan biasanya (%+.1f %s)",
+			verb, math.Abs(v.Value), v.Unit, v.Baseline, v.Unit)
+	case AlertSymptomSeverity:
+		return fmt.Sprintf("Keparahan gejala mencapai %.0f/10, jauh di atas biasanya (%.0f/10)", v.Value, v.Baseline)
+	case AlertWaterIntake:
+		return fmt.Sprintf("Asupan air hanya %.0f %s, jauh di bawah biasanya (%.0f %s)", v.Value, v.Unit, v.Baseline, v.Unit)
+	}
+	return ""
+}
@@ -14,6 +14,16 @@ type Article struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
+// ArticleRequest is the request body for creating or updating an article
+type ArticleRequest struct {
+	Title    string `json:"title" binding:"required,max=200"`
+	Content  string `json:"content" binding:"required"`
+	Summary  string `json:"summary" binding:"max=500"`
+	Category string `json:"category" binding:"required,oneof=nutrisi olahraga mental tidur umum"`
+	ImageURL string `json:"image_url" binding:"max=500"`
+	ReadTime int    `json:"read_time" binding:"min=0"`
+}
+
 // GetSampleArticles returns sample health articles for seeding
 func GetSampleArticles() []Article {
 	return []Article{
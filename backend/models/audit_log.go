@@ -0,0 +1,64 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AuditLog is an append-only record of a security-relevant action.
+// Entries are kept when an account is purged so that access to other
+// people's data stays traceable; they reference users by ID and keep a
+// snapshot of the actor's email.
+type AuditLog struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	ActorID      *uint     `gorm:"index" json:"actor_id"` // nil for system actions and anonymous attempts
+	ActorEmail   string    `gorm:"size:255" json:"actor_email,omitempty"`
+	Action       string    `gorm:"size:64;not null;index" json:"action"`
+	TargetUserID *uint     `gorm:"index" json:"target_user_id"`
+	ResourceType string    `gorm:"size:50" json:"resource_type,omitempty"`
+	ResourceID   string    `gorm:"size:64" json:"resource_id,omitempty"`
+	IPAddress    string    `gorm:"size:64" json:"ip_address,omitempty"`
+	UserAgent    string    `gorm:"size:255" json:"user_agent,omitempty"`
+	Details      string    `gorm:"type:text" json:"details,omitempty"` // JSON object
+	CreatedAt    time.Time `gorm:"index" json:"created_at"`
+}
+
+// ErrAuditLogAppendOnly is returned when code tries to change an audit entry
+var ErrAuditLogAppendOnly = errors.New("audit log entries cannot be modified or deleted")
+
+func (AuditLog) BeforeUpdate(tx *gorm.DB) error { return ErrAuditLogAppendOnly }
+
+func (AuditLog) BeforeDelete(tx *gorm.DB) error { return ErrAuditLogAppendOnly }
+
+// Audit actions
+const (
+	AuditLoginSucceeded = "auth.login.succeeded"
+	AuditLoginFailed    = "auth.login.failed"
+	AuditPasswordReset  = "auth.password.reset"
+	AuditTwoFactorOn    = "auth.2fa.enabled"
+	AuditTwoFactorOff   = "auth.2fa.disabled"
+	AuditAPIKeyCreated  = "auth.api_key.created"
+	AuditAPIKeyRevoked  = "auth.api_key.revoked"
+
+	AuditFamilyHealthViewed = "family.health.viewed"
+
+	AuditExportCreated     = "account.export.created"
+	AuditExportRequested   = "account.export.requested"
+	AuditExportDownloaded  = "account.export.downloaded"
+	AuditDeletionScheduled = "account.deletion.scheduled"
+	AuditDeletionCancelled = "account.deletion.cancelled"
+	AuditAccountPurged     = "account.purged"
+
+	AuditAdminRoleUpdated    = "admin.user.role_updated"
+	AuditAdminArticleCreated = "admin.article.created"
+	AuditAdminArticleUpdated = "admin.article.updated"
+	AuditAdminArticleDeleted = "admin.article.deleted"
+	AuditAdminPostDeleted    = "admin.forum.post_deleted"
+	AuditAdminCommentDeleted = "admin.forum.comment_deleted"
+	AuditAdminSymptomCreated = "admin.symptom_template.created"
+	AuditAdminSymptomUpdated = "admin.symptom_template.updated"
+	AuditAdminSymptomDeleted = "admin.symptom_template.deleted"
+	AuditAdminAuditLogViewed = "admin.audit_log.viewed"
+)
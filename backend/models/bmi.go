@@ -0,0 +1,233 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// BMI classification schemes
+const (
+	BMISchemeWHO           = "who"          // adults: 18.5 / 25 / 30
+	BMISchemeAsiaPacific   = "asia_pacific" // adults: 18.5 / 23 / 25
+	BMISchemeWHOBMIForAge  = "who_bmi_for_age"
+	BMISchemeNotApplicable = "not_applicable" // under 2 years
+)
+
+// BMI categories. Children use the same names for thinness, overweight
+// and obesity so callers can treat every scheme alike.
+const (
+	BMIUnderweight = "Underweight"
+	BMINormal      = "Normal"
+	BMIOverweight  = "Overweight"
+	BMIObese       = "Obese"
+)
+
+// BMIClassification is a BMI value interpreted for the person's age and
+// sex. ZScore and Percentile are only set for children.
+type BMIClassification struct {
+	BMI        float64  `json:"bmi"`
+	Category   string   `json:"category"`
+	Scheme     string   `json:"scheme"`
+	AgeMonths  *int     `json:"age_months,omitempty"`
+	ZScore     *float64 `json:"z_score,omitempty"`
+	Percentile *float64 `json:"percentile,omitempty"`
+	Note       string   `json:"note,omitempty"`
+}
+
+// bmiLMS holds the Box-Cox parameters of the reference distribution at
+// one age: BMI = M * (1 + L*S*z)^(1/L)
+type bmiLMS struct {
+	Months  int
+	L, M, S float64
+}
+
+// WHO BMI-for-age references (2006 Child Growth Standards up to 5 years,
+// 2007 Growth Reference 5-19 years), sampled at whole years. L and S are
+// fitted to the published -2/+1/+2 SD cut-offs and reproduce them within
+// 0.1 kg/m²; ages in between are interpolated linearly.
+var (
+	bmiForAgeBoys = []bmiLMS{
+		{24, -0.62, 16.00, 0.0784},
+		{36, -0.65, 15.60, 0.0786},
+		{48, -0.58, 15.30, 0.0826},
+		{60, -0.74, 15.26, 0.0839},
+		{72, -0.80, 15.30, 0.0881},
+		{84, -1.17, 15.50, 0.0906},
+		{96, -1.48, 15.70, 0.0960},
+		{108, -1.74, 16.00, 0.1006},
+		{120, -1.88, 16.40, 0.1051},
+		{132, -1.91, 16.90, 0.1104},
+		{144, -1.89, 17.50, 0.1141},
+		{156, -1.78, 18.20, 0.1190},
+		{168, -1.62, 19.00, 0.1218},
+		{180, -1.49, 19.80, 0.1242},
+		{192, -1.34, 20.50, 0.1261},
+		{204, -1.20, 21.10, 0.1276},
+		{216, -1.04, 21.70, 0.1278},
+		{228, -0.95, 22.20, 0.1273},
+	}
+	bmiForAgeGirls = []bmiLMS{
+		{24, -0.97, 15.70, 0.0892},
+		{36, -1.07, 15.40, 0.0879},
+		{48, -1.10, 15.20, 0.0925},
+		{60, -0.89, 15.24, 0.0969},
+		{72, -1.05, 15.30, 0.1011},
+		{84, -1.18, 15.40, 0.1087},
+		{96, -1.32, 15.70, 0.1136},
+		{108, -1.45, 16.10, 0.1182},
+		{120, -1.50, 16.60, 0.1232},
+		{132, -1.52, 17.20, 0.1272},
+		{144, -1.42, 18.00, 0.1312},
+		{156, -1.27, 18.80, 0.1354},
+		{168, -1.19, 19.60, 0.1369},
+		{180, -1.12, 20.20, 0.1391},
+		{192, -1.09, 20.70, 0.1399},
+		{204, -0.95, 21.00, 0.1425},
+		{216, -0.86, 21.30, 0.1425},
+		{228, -0.77, 21.40, 0.1449},
+	}
+)
+
+// ClassifyBMI interprets bmi for someone with the given sex and birth
+// date, measured on the given day. From 2 to 19 years it uses WHO
+// BMI-for-age z-scores; adults, and anyone without a birth date, use
+// adultScheme. Unknown sex averages the boys' and girls' z-scores.
+func ClassifyBMI(bmi float64, sex string, birthDate, measuredOn time.Time, adultScheme string) BMIClassification {
+	if birthDate.IsZero() || bmi <= 0 {
+		return classifyAdultBMI(bmi, adultScheme)
+	}
+
+	months := ageInMonths(birthDate, measuredOn)
+	switch {
+	case months < 24:
+		return BMIClassification{
+			BMI:       bmi,
+			Scheme:    BMISchemeNotApplicable,
+			AgeMonths: &months,
+			Note:      "BMI is not interpreted under 2 years; use weight-for-length instead",
+		}
+	case months >= 240:
+		result := classifyAdultBMI(bmi, adultScheme)
+		result.AgeMonths = &months
+		return result
+	}
+
+	var z float64
+	switch sex {
+	case SexMale:
+		z = bmiZScore(bmi, lmsAt(bmiForAgeBoys, months))
+	case SexFemale:
+		z = bmiZScore(bmi, lmsAt(bmiForAgeGirls, months))
+	default:
+		z = (bmiZScore(bmi, lmsAt(bmiForAgeBoys, months)) + bmiZScore(bmi, lmsAt(bmiForAgeGirls, months))) / 2
+	}
+	z = math.Round(z*100) / 100
+	percentile := math.Round(50*(1+math.Erf(z/math.Sqrt2))*10) / 10
+
+	result := BMIClassification{
+		BMI:        bmi,
+		Scheme:     BMISchemeWHOBMIForAge,
+		AgeMonths:  &months,
+		ZScore:     &z,
+		Percentile: &percentile,
+	}
+	if sex == "" {
+		result.Note = "sex unknown, using the average of the boys' and girls' references"
+	}
+
+	// Under 5 the 2006 standards flag overweight at +2 SD and obesity at
+	// +3 SD; from 5 years the 2007 reference uses +1 SD and +2 SD.
+	overweight, obese := 1.0, 2.0
+	if months < 60 {
+		overweight, obese = 2, 3
+	}
+	switch {
+	case z < -2:
+		result.Category = BMIUnderweight
+	case z > obese:
+		result.Category = BMIObese
+	case z > overweight:
+		result.Category = BMIOverweight
+	default:
+		result.Category = BMINormal
+	}
+	return result
+}
+
+// GetBMICategory classifies an adult BMI with the WHO cut-offs
+func GetBMICategory(bmi float64) string {
+	return classifyAdultBMI(bmi, BMISchemeWHO).Category
+}
+
+func classifyAdultBMI(bmi float64, scheme string) BMIClassification {
+	overweight, obese := 25.0, 30.0
+	if scheme == BMISchemeAsiaPacific {
+		overweight, obese = 23, 25
+	} else {
+		scheme = BMISchemeWHO
+	}
+
+	result := BMIClassification{BMI: bmi, Scheme: scheme}
+	switch {
+	case bmi < 18.5:
+		result.Category = BMIUnderweight
+	case bmi < overweight:
+		result.Category = BMINormal
+	case bmi < obese:
+		result.Category = BMIOverweight
+	default:
+		result.Category = BMIObese
+	}
+	return result
+}
+
+// lmsAt interpolates the reference between the surrounding whole years.
+// Ages past the last entry use the last entry.
+func lmsAt(table []bmiLMS, months int) bmiLMS {
+	if months <= table[0].Months {
+		return table[0]
+	}
+	for i := 1; i < len(table); i++ {
+		if months <= table[i].Months {
+			a, b := table[i-1], table[i]
+			f := float64(months-a.Months) / float64(b.Months-a.Months)
+			return bmiLMS{
+				Months: months,
+				L:      a.L + f*(b.L-a.L),
+				M:      a.M + f*(b.M-a.M),
+				S:      a.S + f*(b.S-a.S),
+			}
+		}
+	}
+	return table[len(table)-1]
+}
+
+// bmiZScore applies the LMS formula. Beyond ±3 SD WHO measures distance
+// in units of the 2-3 SD interval to keep the skewed tail from
+// inflating the score.
+func bmiZScore(bmi float64, r bmiLMS) float64 {
+	sd := func(z float64) float64 {
+		return r.M * math.Pow(1+r.L*r.S*z, 1/r.L)
+	}
+
+	z := (math.Pow(bmi/r.M, r.L) - 1) / (r.L * r.S)
+	switch {
+	case z > 3:
+		return 3 + (bmi-sd(3))/(sd(3)-sd(2))
+	case z < -3:
+		return -3 + (bmi-sd(-3))/(sd(-2)-sd(-3))
+	}
+	return z
+}
+
+// ageInMonths returns completed months of age on the given day
+func ageInMonths(birthDate, day time.Time) int {
+	months := (day.Year()-birthDate.Year())*12 + int(day.Month()-birthDate.Month())
+	if day.Day() < birthDate.Day() {
+		months--
+	}
+	if months < 0 {
+		return 0
+	}
+	return months
+}
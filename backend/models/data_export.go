@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// DataExport is a background account export job. The finished archive is
+// kept on disk until ExpiresAt and can be fetched with the emailed link.
+type DataExport struct {
+	ID                uint       `gorm:"primaryKey" json:"id"`
+	UserID            uint       `gorm:"not null;index" json:"user_id"`
+	Status            string     `gorm:"size:20;not null;index" json:"status"`
+	Records           int64      `json:"records"`
+	SizeBytes         int64      `json:"size_bytes"`
+	FilePath          string     `gorm:"size:255" json:"-"`
+	DownloadTokenHash string     `gorm:"size:64;index" json:"-"`
+	Error             string     `gorm:"size:255" json:"error,omitempty"`
+	ExpiresAt         *time.Time `json:"expires_at"`
+	CompletedAt       *time.Time `json:"completed_at"`
+	CreatedAt         time.Time  `json:"created_at"`
+}
+
+// Data export statuses
+const (
+	ExportStatusPending    = "pending"
+	ExportStatusProcessing = "processing"
+	ExportStatusReady      = "ready"
+	ExportStatusFailed     = "failed"
+	ExportStatusExpired    = "expired"
+)
@@ -0,0 +1,64 @@
+package models
+
+// Biological sex, used for energy equations
+const (
+	SexMale   = "male"
+	SexFemale = "female"
+)
+
+// Weight goal directions
+const (
+	EnergyGoalLose     = "lose"
+	EnergyGoalMaintain = "maintain"
+	EnergyGoalGain     = "gain"
+)
+
+// ActivityMultipliers convert BMR to total daily energy expenditure
+var ActivityMultipliers = map[string]float64{
+	ActivitySedentary: 1.2,
+	ActivityLight:     1.375,
+	ActivityModerate:  1.55,
+	ActivityActive:    1.725,
+}
+
+// MinimumCalories are the lowest daily targets suggested without medical
+// supervision. The unknown-sex value is the midpoint.
+var MinimumCalories = map[string]float64{
+	SexMale:   1500,
+	SexFemale: 1200,
+	"":        1350,
+}
+
+// EnergyTargets explains how a user's daily calorie and macronutrient
+// targets were derived
+type EnergyTargets struct {
+	Method          string    `json:"method"` // mifflin_st_jeor, or weight_based when height is unknown
+	Sex             string    `json:"sex,omitempty"`
+	AgeYears        int       `json:"age_years"`
+	WeightKg        float64   `json:"weight_kg"`
+	HeightCm        float64   `json:"height_cm"`
+	ActivityLevel   string    `json:"activity_level"`
+	ActivityFactor  float64   `json:"activity_factor"`
+	BMR             float64   `json:"bmr"`
+	TDEE            float64   `json:"tdee"`
+	Goal            string    `json:"goal"` // lose, maintain, gain
+	GoalID          *uint     `json:"goal_id,omitempty"`
+	GoalWeightKg    float64   `json:"goal_weight_kg,omitempty"`
+	DailyAdjustment float64   `json:"daily_adjustment"` // kcal added to TDEE, negative for a deficit
+	Target          Nutrients `json:"target"`
+	Assumptions     []string  `json:"assumptions,omitempty"`
+}
+
+// CalculateBMR uses the Mifflin-St Jeor equation. For unknown sex the
+// constant is the average of the male (+5) and female (-161) values.
+func CalculateBMR(sex string, weightKg, heightCm float64, ageYears int) float64 {
+	bmr := 10*weightKg + 6.25*heightCm - 5*float64(ageYears)
+	switch sex {
+	case SexMale:
+		return bmr + 5
+	case SexFemale:
+		return bmr - 161
+	default:
+		return bmr - 78
+	}
+}
@@ -32,9 +32,11 @@ type FamilyMemberResponse struct {
 }
 
 type FamilyHealthView struct {
-	MemberName     string       `json:"member_name"`
-	Relationship   string       `json:"relationship"`
-	LatestHealth   *HealthData  `json:"latest_health"`
-	BMICategory    string       `json:"bmi_category"`
-	RecentSymptoms []Symptom    `json:"recent_symptoms"`
+	MemberName     string            `json:"member_name"`
+	Relationship   string            `json:"relationship"`
+	LatestHealth   *HealthDataView   `json:"latest_health"`
+	BMICategory    string            `json:"bmi_category"`
+	BMI            BMIClassification `json:"bmi_classification"`
+	RecentSymptoms []Symptom         `json:"recent_symptoms"`
+	Alerts         []HealthAlertView `json:"alerts"`
 }
@@ -0,0 +1,240 @@
+package models
+
+import "time"
+
+// Meal types
+const (
+	MealBreakfast = "breakfast"
+	MealLunch     = "lunch"
+	MealDinner    = "dinner"
+	MealSnack     = "snack"
+)
+
+// MealTypes lists meal types in the order of a day
+var MealTypes = []string{MealBreakfast, MealLunch, MealDinner, MealSnack}
+
+// Food is an entry in the nutrition database. Nutrients are per 100 g
+// (or 100 ml for drinks) of the food as eaten.
+type Food struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	Name         string    `gorm:"size:100;uniqueIndex;not null" json:"name"`
+	Category     string    `gorm:"size:32;index" json:"category"` // pokok, lauk, sayur, buah, jajanan, minuman
+	ServingSize  string    `gorm:"size:50" json:"serving_size"`   // e.g. "1 piring"
+	ServingGrams float64   `json:"serving_grams"`
+	Calories     float64   `json:"calories"`
+	ProteinG     float64   `json:"protein_g"`
+	CarbsG       float64   `json:"carbs_g"`
+	FatG         float64   `json:"fat_g"`
+	FiberG       float64   `json:"fiber_g"`
+	SodiumMg     float64   `json:"sodium_mg"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// MealLog is a portion of a food the user ate. The food name and
+// nutrients are copied at logging time so later edits to the food table
+// do not change past days.
+type MealLog struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;index:idx_meal_user_time" json:"user_id"`
+	FoodID    uint      `gorm:"not null" json:"food_id"`
+	FoodName  string    `gorm:"size:100" json:"food_name"`
+	MealType  string    `gorm:"size:16;not null" json:"meal_type"`
+	Grams     float64   `gorm:"not null" json:"grams"`
+	Calories  float64   `json:"calories"`
+	ProteinG  float64   `json:"protein_g"`
+	CarbsG    float64   `json:"carbs_g"`
+	FatG      float64   `json:"fat_g"`
+	FiberG    float64   `json:"fiber_g"`
+	SodiumMg  float64   `json:"sodium_mg"`
+	Notes     string    `json:"notes"`
+	EatenAt   time.Time `gorm:"not null;index:idx_meal_user_time" json:"eaten_at"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// MealLogRequest logs a portion either in grams or in servings of the
+// food's serving size. One serving is assumed when neither is given.
+type MealLogRequest struct {
+	FoodID   uint     `json:"food_id" binding:"required"`
+	MealType string   `json:"meal_type" binding:"required,oneof=breakfast lunch dinner snack"`
+	Grams    *float64 `json:"grams" binding:"omitempty,gt=0,lte=5000"`
+	Servings *float64 `json:"servings" binding:"omitempty,gt=0,lte=20"`
+	Notes    string   `json:"notes"`
+	EatenAt  string   `json:"eaten_at"` // optional, YYYY-MM-DD or RFC 3339; defaults to now
+}
+
+// Nutrients is an amount of energy and macronutrients
+type Nutrients struct {
+	Calories float64 `json:"calories"`
+	ProteinG float64 `json:"protein_g"`
+	CarbsG   float64 `json:"carbs_g"`
+	FatG     float64 `json:"fat_g"`
+	FiberG   float64 `json:"fiber_g"`
+	SodiumMg float64 `json:"sodium_mg"`
+}
+
+// DailyNutrition compares a day's meals with the user's targets
+type DailyNutrition struct {
+	Date              string               `json:"date"`
+	Target            Nutrients            `json:"target"`
+	Totals            Nutrients            `json:"totals"`
+	Remaining         Nutrients            `json:"remaining"`
+	CaloriePercentage float64              `json:"calorie_percentage"`
+	ByMeal            map[string]Nutrients `json:"by_meal"`
+	Meals             []MealLog            `json:"meals"`
+}
+
+// Portion returns the nutrients in the given weight of the food
+func (f *Food) Portion(grams float64) Nutrients {
+	factor := grams / 100
+	return Nutrients{
+		Calories: f.Calories * factor,
+		ProteinG: f.ProteinG * factor,
+		CarbsG:   f.CarbsG * factor,
+		FatG:     f.FatG * factor,
+		FiberG:   f.FiberG * factor,
+		SodiumMg: f.SodiumMg * factor,
+	}
+}
+
+// Nutrients returns the nutrients of the logged portion
+func (m *MealLog) Nutrients() Nutrients {
+	return Nutrients{
+		Calories: m.Calories,
+		ProteinG: m.ProteinG,
+		CarbsG:   m.CarbsG,
+		FatG:     m.FatG,
+		FiberG:   m.FiberG,
+		SodiumMg: m.SodiumMg,
+	}
+}
+
+// Add returns the sum of two amounts
+func (n Nutrients) Add(o Nutrients) Nutrients {
+	return Nutrients{
+		Calories: n.Calories + o.Calories,
+		ProteinG: n.ProteinG + o.ProteinG,
+		CarbsG:   n.CarbsG + o.CarbsG,
+		FatG:     n.FatG + o.FatG,
+		FiberG:   n.FiberG + o.FiberG,
+		SodiumMg: n.SodiumMg + o.SodiumMg,
+	}
+}
+
+// Sub returns n minus o
+func (n Nutrients) Sub(o Nutrients) Nutrients {
+	return Nutrients{
+		Calories: n.Calories - o.Calories,
+		ProteinG: n.ProteinG - o.ProteinG,
+		CarbsG:   n.CarbsG - o.CarbsG,
+		FatG:     n.FatG - o.FatG,
+		FiberG:   n.FiberG - o.FiberG,
+		SodiumMg: n.SodiumMg - o.SodiumMg,
+	}
+}
+
+func food(name, category, servingSize string, servingGrams, calories, protein, carbs, fat, fiber, sodium float64) Food {
+	return Food{
+		Name:         name,
+		Category:     category,
+		ServingSize:  servingSize,
+		ServingGrams: servingGrams,
+		Calories:     calories,
+		ProteinG:     protein,
+		CarbsG:       carbs,
+		FatG:         fat,
+		FiberG:       fiber,
+		SodiumMg:     sodium,
+	}
+}
+
+// GetSeedFoods returns the initial nutrition database. Values per 100 g
+// are based on Tabel Komposisi Pangan Indonesia (TKPI) and USDA
+// FoodData Central; mixed dishes are typical home-style recipes.
+func GetSeedFoods() []Food {
+	return []Food{
+		// name, category, serving, serving g, kcal, protein, carbs, fat, fiber, sodium mg
+		food("Nasi Putih", "pokok", "1 piring", 150, 180, 3.0, 39.8, 0.3, 0.2, 1),
+		food("Nasi Merah", "pokok", "1 piring", 150, 149, 2.8, 32.5, 0.4, 1.8, 3),
+		food("Nasi Goreng", "pokok", "1 piring", 250, 186, 6.0, 24.0, 7.3, 0.8, 380),
+		food("Nasi Uduk", "pokok", "1 piring", 200, 200, 3.5, 32.0, 6.5, 0.5, 180),
+		food("Bubur Ayam", "pokok", "1 mangkuk", 300, 72, 3.8, 11.0, 1.5, 0.3, 250),
+		food("Lontong", "pokok", "1 buah", 150, 110, 2.0, 24.5, 0.2, 0.3, 2),
+		food("Mie Goreng", "pokok", "1 piring", 200, 190, 4.5, 25.0, 8.0, 1.2, 450),
+		food("Mie Ayam", "pokok", "1 mangkuk", 350, 105, 5.5, 14.0, 3.0, 0.8, 380),
+		food("Roti Tawar", "pokok", "2 lembar", 50, 248, 8.0, 50.0, 1.2, 2.4, 530),
+		food("Kentang Rebus", "pokok", "1 buah sedang", 150, 87, 1.9, 20.1, 0.1, 1.8, 5),
+		food("Singkong Rebus", "pokok", "1 potong", 100, 146, 1.2, 34.9, 0.3, 1.8, 14),
+		food("Ubi Jalar Rebus", "pokok", "1 buah", 100, 86, 1.6, 20.1, 0.1, 3.0, 27),
+		food("Jagung Rebus", "pokok", "1 tongkol", 100, 96, 3.4, 21.0, 1.5, 2.4, 1),
+		food("Oatmeal", "pokok", "1 mangkuk", 240, 71, 2.5, 12.0, 1.5, 1.7, 49),
+
+		food("Ayam Goreng", "lauk", "1 potong", 80, 260, 27.0, 5.0, 16.0, 0.2, 350),
+		food("Ayam Bakar", "lauk", "1 potong", 100, 190, 25.0, 4.0, 8.0, 0.3, 420),
+		food("Dada Ayam Rebus", "lauk", "1 potong", 100, 165, 31.0, 0, 3.6, 0, 74),
+		food("Opor Ayam", "lauk", "1 porsi", 150, 165, 14.0, 3.0, 11.0, 0.4, 380),
+		food("Gulai Ayam", "lauk", "1 porsi", 150, 170, 13.0, 4.0, 11.5, 0.8, 400),
+		food("Rendang Daging", "lauk", "1 potong", 75, 193, 22.6, 7.8, 7.9, 1.4, 450),
+		food("Sate Ayam", "lauk", "5 tusuk", 100, 225, 20.0, 8.0, 13.0, 1.0, 480),
+		food("Sate Kambing", "lauk", "5 tusuk", 100, 230, 22.0, 5.0, 14.0, 0.5, 400),
+		food("Ikan Kembung Goreng", "lauk", "1 ekor", 100, 210, 21.0, 3.0, 13.0, 0, 200),
+		food("Ikan Bakar", "lauk", "1 ekor", 150, 140, 24.0, 2.0, 4.0, 0, 280),
+		food("Pepes Ikan", "lauk", "1 bungkus", 100, 120, 18.0, 3.0, 4.0, 0.8, 320),
+		food("Telur Rebus", "lauk", "1 butir", 55, 154, 12.4, 0.7, 10.8, 0, 142),
+		food("Telur Dadar", "lauk", "1 lembar", 65, 188, 11.5, 1.5, 15.0, 0, 350),
+		food("Telur Balado", "lauk", "1 butir", 70, 190, 11.0, 5.0, 14.0, 0.6, 400),
+		food("Tempe Goreng", "lauk", "1 potong", 50, 280, 18.5, 12.0, 18.0, 1.5, 25),
+		food("Tempe Bacem", "lauk", "1 potong", 50, 220, 14.0, 20.0, 9.0, 1.5, 300),
+		food("Tempe Mendoan", "lauk", "1 potong", 60, 270, 10.0, 22.0, 16.0, 1.5, 250),
+		food("Tahu Goreng", "lauk", "1 potong", 50, 175, 11.0, 5.0, 12.0, 0.5, 20),
+		food("Tahu Kukus", "lauk", "1 potong", 50, 80, 10.9, 0.8, 4.7, 0.1, 7),
+		food("Perkedel Kentang", "lauk", "1 buah", 50, 200, 4.0, 20.0, 12.0, 1.5, 300),
+
+		food("Bakso Kuah", "hidangan", "1 mangkuk", 350, 76, 5.5, 6.0, 3.4, 0.3, 350),
+		food("Soto Ayam", "hidangan", "1 mangkuk", 300, 60, 5.0, 3.5, 3.0, 0.4, 330),
+		food("Rawon", "hidangan", "1 mangkuk", 300, 90, 8.0, 3.0, 5.0, 0.6, 350),
+		food("Gado-gado", "hidangan", "1 porsi", 250, 135, 6.0, 10.0, 8.0, 3.0, 300),
+		food("Pecel", "hidangan", "1 porsi", 200, 120, 5.5, 9.0, 7.5, 3.4, 280),
+		food("Ketoprak", "hidangan", "1 porsi", 300, 150, 6.0, 18.0, 6.0, 2.0, 350),
+		food("Siomay", "hidangan", "1 porsi", 200, 160, 9.0, 18.0, 5.5, 1.2, 420),
+		food("Batagor", "hidangan", "1 porsi", 150, 250, 8.0, 22.0, 14.0, 1.2, 400),
+		food("Pempek", "hidangan", "1 porsi", 150, 200, 8.0, 32.0, 4.0, 0.5, 400),
+
+		food("Sayur Asem", "sayur", "1 mangkuk", 200, 30, 1.0, 6.0, 0.3, 1.6, 250),
+		food("Sayur Lodeh", "sayur", "1 mangkuk", 200, 70, 2.0, 5.0, 5.0, 1.8, 280),
+		food("Sayur Sop", "sayur", "1 mangkuk", 250, 40, 3.0, 3.0, 2.0, 0.8, 300),
+		food("Capcay", "sayur", "1 porsi", 200, 60, 3.0, 5.0, 3.0, 1.5, 350),
+		food("Tumis Kangkung", "sayur", "1 porsi", 100, 60, 2.5, 4.5, 4.0, 2.2, 380),
+		food("Bayam Rebus", "sayur", "1 mangkuk", 100, 23, 3.0, 3.8, 0.3, 2.4, 70),
+		food("Brokoli Rebus", "sayur", "1 mangkuk", 100, 35, 2.4, 7.2, 0.4, 3.3, 41),
+		food("Wortel Rebus", "sayur", "1 mangkuk", 100, 35, 0.8, 8.2, 0.2, 3.0, 58),
+		food("Timun", "sayur", "1 buah", 100, 15, 0.7, 3.6, 0.1, 0.5, 2),
+
+		food("Pisang", "buah", "1 buah", 100, 89, 1.1, 22.8, 0.3, 2.6, 1),
+		food("Pepaya", "buah", "1 potong", 150, 43, 0.5, 10.8, 0.3, 1.7, 8),
+		food("Apel", "buah", "1 buah", 180, 52, 0.3, 13.8, 0.2, 2.4, 1),
+		food("Jeruk", "buah", "1 buah", 130, 47, 0.9, 11.8, 0.1, 2.4, 0),
+		food("Mangga", "buah", "1 buah", 200, 60, 0.8, 15.0, 0.4, 1.6, 1),
+		food("Semangka", "buah", "1 potong", 200, 30, 0.6, 7.6, 0.2, 0.4, 1),
+		food("Alpukat", "buah", "1/2 buah", 100, 160, 2.0, 8.5, 14.7, 6.7, 7),
+
+		food("Martabak Manis", "jajanan", "1 potong", 75, 320, 6.0, 45.0, 13.0, 1.2, 300),
+		food("Martabak Telur", "jajanan", "1 potong", 80, 250, 9.0, 20.0, 15.0, 1.0, 400),
+		food("Bakwan", "jajanan", "1 buah", 40, 280, 5.0, 28.0, 16.0, 2.0, 300),
+		food("Pisang Goreng", "jajanan", "1 buah", 75, 230, 2.0, 34.0, 10.0, 2.2, 80),
+		food("Kerupuk Udang", "jajanan", "1 keping", 10, 500, 10.0, 65.0, 23.0, 0.5, 1100),
+		food("Yogurt Plain", "jajanan", "1 cup", 150, 61, 3.5, 4.7, 3.3, 0, 46),
+		food("Kacang Almond", "jajanan", "1 genggam", 28, 579, 21.0, 22.0, 50.0, 12.5, 1),
+
+		food("Air Putih", "minuman", "1 gelas", 250, 0, 0, 0, 0, 0, 0),
+		food("Teh Manis", "minuman", "1 gelas", 250, 32, 0, 8.0, 0, 0, 2),
+		food("Teh Tawar", "minuman", "1 gelas", 250, 1, 0, 0.3, 0, 0, 3),
+		food("Kopi Hitam", "minuman", "1 cangkir", 200, 2, 0.3, 0, 0, 0, 5),
+		food("Kopi Susu Gula Aren", "minuman", "1 gelas", 250, 70, 1.5, 11.0, 2.2, 0, 35),
+		food("Susu Sapi", "minuman", "1 gelas", 250, 61, 3.2, 4.8, 3.3, 0, 43),
+		food("Susu Kedelai", "minuman", "1 gelas", 250, 40, 3.0, 3.0, 1.8, 0.6, 50),
+		food("Jus Jeruk", "minuman", "1 gelas", 250, 45, 0.7, 10.4, 0.2, 0.2, 1),
+		food("Air Kelapa", "minuman", "1 gelas", 250, 19, 0.7, 3.7, 0.2, 1.1, 105),
+	}
+}
@@ -0,0 +1,273 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// Weight goal statuses
+const (
+	ForecastAchieved         = "achieved"
+	ForecastOnTrack          = "on_track"
+	ForecastBehind           = "behind"
+	ForecastInsufficientData = "insufficient_data"
+)
+
+// forecastHorizonDays is how far ahead a target date is searched for
+const forecastHorizonDays = 730
+
+// trendHalfLifeDays sets how quickly the smoothed trend follows new
+// weigh-ins
+const trendHalfLifeDays = 7
+
+// WeightSample is one weigh-in
+type WeightSample struct {
+	Time     time.Time
+	WeightKg float64
+}
+
+// WeightTrendPoint is a weigh-in with its smoothed trend value
+type WeightTrendPoint struct {
+	Date   string  `json:"date"`
+	Weight float64 `json:"weight"`
+	Trend  float64 `json:"trend"`
+}
+
+// WeightProjectionPoint is a projected weight with its 95% band
+type WeightProjectionPoint struct {
+	Date   string  `json:"date"`
+	Weight float64 `json:"weight"`
+	Low    float64 `json:"low"`
+	High   float64 `json:"high"`
+}
+
+// WeightForecast describes the recent weight trend and when it reaches
+// the target. Weights are in Unit; dates are YYYY-MM-DD.
+type WeightForecast struct {
+	Unit                string                  `json:"unit"`
+	WindowDays          int                     `json:"window_days"`
+	Samples             int                     `json:"samples"`
+	LatestWeight        float64                 `json:"latest_weight"`
+	TrendWeight         float64                 `json:"trend_weight"`  // exponentially smoothed
+	RatePerWeek         float64                 `json:"rate_per_week"` // regression slope, negative when losing
+	RateLow             float64                 `json:"rate_low"`      // 95% interval of the slope
+	RateHigh            float64                 `json:"rate_high"`
+	GoalID              *uint                   `json:"goal_id,omitempty"`
+	TargetWeight        *float64                `json:"target_weight,omitempty"`
+	Deadline            string                  `json:"deadline,omitempty"`
+	RequiredRatePerWeek *float64                `json:"required_rate_per_week,omitempty"`
+	ProjectedDate       string                  `json:"projected_date,omitempty"`
+	EarliestDate        string                  `json:"earliest_date,omitempty"`
+	LatestDate          string                  `json:"latest_date,omitempty"` // empty when the slow end of the band never gets there
+	Status              string                  `json:"status,omitempty"`
+	Trend               []WeightTrendPoint      `json:"trend"`
+	Projection          []WeightProjectionPoint `json:"projection,omitempty"`
+}
+
+// ForecastWeight fits a least-squares line to the samples from the last
+// windowDays and projects it forward. targetKg (0 for none) and deadline
+// (YYYY-MM-DD or empty) decide the status. Samples must be sorted by time.
+// All weights in the result are in kg.
+func ForecastWeight(samples []WeightSample, now time.Time, windowDays int, targetKg float64, deadline string) WeightForecast {
+	f := WeightForecast{Unit: "kg", WindowDays: windowDays, Trend: []WeightTrendPoint{}}
+	if targetKg > 0 {
+		f.TargetWeight = &targetKg
+	}
+	f.Deadline = deadline
+
+	var trend float64
+	for i, s := range samples {
+		if i == 0 {
+			trend = s.WeightKg
+		} else {
+			days := s.Time.Sub(samples[i-1].Time).Hours() / 24
+			alpha := 1 - math.Pow(0.5, days/trendHalfLifeDays)
+			trend += alpha * (s.WeightKg - trend)
+		}
+		f.Trend = append(f.Trend, WeightTrendPoint{Date: s.Time.Format("2006-01-02"), Weight: s.WeightKg, Trend: trend})
+	}
+	if len(samples) > 0 {
+		f.LatestWeight = samples[len(samples)-1].WeightKg
+		f.TrendWeight = trend
+	}
+
+	// Regression over the window, x in days before now
+	since := now.AddDate(0, 0, -windowDays)
+	var xs, ys []float64
+	for _, s := range samples {
+		if !s.Time.Before(since) {
+			xs = append(xs, -now.Sub(s.Time).Hours()/24)
+			ys = append(ys, s.WeightKg)
+		}
+	}
+	f.Samples = len(xs)
+
+	fit, ok := fitLine(xs, ys)
+	if !ok {
+		f.Status = ForecastInsufficientData
+		return f
+	}
+	f.RatePerWeek = fit.slope * 7
+	f.RateLow = (fit.slope - 1.96*fit.slopeSE) * 7
+	f.RateHigh = (fit.slope + 1.96*fit.slopeSE) * 7
+
+	horizon := 84
+	if end, err := time.ParseInLocation("2006-01-02", deadline, now.Location()); err == nil {
+		if days := int(math.Ceil(end.Sub(now).Hours() / 24)); days > horizon {
+			horizon = days
+		}
+	}
+	if horizon > 365 {
+		horizon = 365
+	}
+	for d := 7; d <= horizon; d += 7 {
+		mean, band := fit.at(float64(d))
+		f.Projection = append(f.Projection, WeightProjectionPoint{
+			Date:   now.AddDate(0, 0, d).Format("2006-01-02"),
+			Weight: mean,
+			Low:    mean - band,
+			High:   mean + band,
+		})
+	}
+
+	if targetKg <= 0 {
+		return f
+	}
+
+	current, _ := fit.at(0)
+	losing := targetKg < current
+	reached := func(w float64) bool {
+		if losing {
+			return w <= targetKg
+		}
+		return w >= targetKg
+	}
+	if reached(current) || math.Abs(current-targetKg) < 0.5 {
+		f.Status = ForecastAchieved
+		return f
+	}
+
+	// Walk forward a day at a time: the mean line gives the projected
+	// date, the fast and slow edges of the band give the range
+	for d := 1; d <= forecastHorizonDays; d++ {
+		mean, band := fit.at(float64(d))
+		fast, slow := mean-band, mean+band
+		if !losing {
+			fast, slow = slow, fast
+		}
+		date := now.AddDate(0, 0, d).Format("2006-01-02")
+		if f.EarliestDate == "" && reached(fast) {
+			f.EarliestDate = date
+		}
+		if f.ProjectedDate == "" && reached(mean) {
+			f.ProjectedDate = date
+		}
+		if f.LatestDate == "" && reached(slow) {
+			f.LatestDate = date
+			break
+		}
+	}
+
+	if f.ProjectedDate == "" {
+		f.EarliestDate = ""
+	}
+
+	f.Status = ForecastOnTrack
+	if end, err := time.ParseInLocation("2006-01-02", deadline, now.Location()); err == nil {
+		if days := end.Sub(now).Hours() / 24; days > 0 {
+			required := (targetKg - current) / days * 7
+			f.RequiredRatePerWeek = &required
+		}
+		if f.ProjectedDate == "" || f.ProjectedDate > deadline {
+			f.Status = ForecastBehind
+		}
+	} else if f.ProjectedDate == "" {
+		f.Status = ForecastBehind
+	}
+	return f
+}
+
+// InUnit converts every weight in the forecast with convert
+func (f WeightForecast) InUnit(unit string, convert func(float64) float64) WeightForecast {
+	round := func(v float64) float64 { return math.Round(convert(v)*100) / 100 }
+	roundPtr := func(v *float64) *float64 {
+		if v == nil {
+			return nil
+		}
+		x := round(*v)
+		return &x
+	}
+
+	f.Unit = unit
+	f.LatestWeight = round(f.LatestWeight)
+	f.TrendWeight = round(f.TrendWeight)
+	f.RatePerWeek = round(f.RatePerWeek)
+	f.RateLow = round(f.RateLow)
+	f.RateHigh = round(f.RateHigh)
+	f.TargetWeight = roundPtr(f.TargetWeight)
+	f.RequiredRatePerWeek = roundPtr(f.RequiredRatePerWeek)
+
+	trend := make([]WeightTrendPoint, len(f.Trend))
+	for i, p := range f.Trend {
+		trend[i] = WeightTrendPoint{Date: p.Date, Weight: round(p.Weight), Trend: round(p.Trend)}
+	}
+	f.Trend = trend
+	projection := make([]WeightProjectionPoint, len(f.Projection))
+	for i, p := range f.Projection {
+		projection[i] = WeightProjectionPoint{Date: p.Date, Weight: round(p.Weight), Low: round(p.Low), High: round(p.High)}
+	}
+	f.Projection = projection
+	return f
+}
+
+// lineFit is an ordinary least-squares fit y = intercept + slope*x
+type lineFit struct {
+	intercept, slope float64
+	slopeSE          float64
+	residualSD       float64
+	n                float64
+	meanX, sxx       float64
+}
+
+// fitLine needs at least three points spread over at least three days
+func fitLine(xs, ys []float64) (lineFit, bool) {
+	n := float64(len(xs))
+	if len(xs) < 3 || xs[len(xs)-1]-xs[0] < 3 {
+		return lineFit{}, false
+	}
+
+	var sumX, sumY float64
+	for i := range xs {
+		sumX += xs[i]
+		sumY += ys[i]
+	}
+	meanX, meanY := sumX/n, sumY/n
+
+	var sxx, sxy float64
+	for i := range xs {
+		sxx += (xs[i] - meanX) * (xs[i] - meanX)
+		sxy += (xs[i] - meanX) * (ys[i] - meanY)
+	}
+	if sxx == 0 {
+		return lineFit{}, false
+	}
+
+	fit := lineFit{slope: sxy / sxx, n: n, meanX: meanX, sxx: sxx}
+	fit.intercept = meanY - fit.slope*meanX
+
+	var sse float64
+	for i := range xs {
+		r := ys[i] - (fit.intercept + fit.slope*xs[i])
+		sse += r * r
+	}
+	fit.residualSD = math.Sqrt(sse / (n - 2))
+	fit.slopeSE = fit.residualSD / math.Sqrt(sxx)
+	return fit, true
+}
+
+// at returns the fitted value at x and the half-width of its 95%
+// confidence interval
+func (l lineFit) at(x float64) (float64, float64) {
+	se := l.residualSD * math.Sqrt(1/l.n+(x-l.meanX)*(x-l.meanX)/l.sxx)
+	return l.intercept + l.slope*x, 1.96 * se
+}
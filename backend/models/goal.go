@@ -31,6 +31,10 @@ type GoalResponse struct {
 	IsCompleted bool    `json:"is_completed"`
 	Progress    float64 `json:"progress"` // percentage
 	DaysLeft    int     `json:"days_left"`
+
+	// Weight goals only, from the recent weight trend
+	Status        string `json:"status,omitempty"`         // achieved, on_track, behind, insufficient_data
+	ProjectedDate string `json:"projected_date,omitempty"` // when the trend reaches the target
 }
 
 // GetProgress calculates the progress percentage
@@ -2,6 +2,8 @@ package models
 
 import (
 	"time"
+
+	"health-tracker/units"
 )
 
 type HealthData struct {
@@ -18,23 +20,47 @@ type HealthData struct {
 	CreatedAt      time.Time `json:"created_at"`
 }
 
+// HealthDataRequest takes weight and height either as weight_kg and
+// height_cm, or as weight and height in weight_unit and height_unit
+// (default: the user's preferred units). Heights in ft_in are given in
+// inches.
 type HealthDataRequest struct {
-	WeightKg       float64 `json:"weight_kg" binding:"required"`
-	HeightCm       float64 `json:"height_cm" binding:"required"`
-	ActivityLevel  string  `json:"activity_level"`
-	EmotionalState string  `json:"emotional_state"`
-	DailySchedule  string  `json:"daily_schedule"`
-	Notes          string  `json:"notes"`
+	WeightKg       float64  `json:"weight_kg"`
+	HeightCm       float64  `json:"height_cm"`
+	Weight         *float64 `json:"weight" binding:"omitempty,gt=0"`
+	WeightUnit     string   `json:"weight_unit" binding:"omitempty,oneof=kg lb"`
+	Height         *float64 `json:"height" binding:"omitempty,gt=0"`
+	HeightUnit     string   `json:"height_unit" binding:"omitempty,oneof=cm in ft_in"`
+	ActivityLevel  string   `json:"activity_level"`
+	EmotionalState string   `json:"emotional_state"`
+	DailySchedule  string   `json:"daily_schedule"`
+	Notes          string   `json:"notes"`
+	RecordDate     string   `json:"record_date"` // optional, YYYY-MM-DD or RFC 3339; defaults to now
+}
+
+// HealthDataView is a health record with weight and height also given in
+// the user's preferred units
+type HealthDataView struct {
+	HealthData
+	Weight        float64 `json:"weight"`
+	WeightUnit    string  `json:"weight_unit"`
+	Height        float64 `json:"height"`
+	HeightUnit    string  `json:"height_unit"`
+	HeightDisplay string  `json:"height_display"`
 }
 
 type DashboardData struct {
-	LatestHealth    *HealthData          `json:"latest_health"`
+	LatestHealth    *HealthDataView      `json:"latest_health"`
 	BMICategory     string               `json:"bmi_category"`
+	BMI             BMIClassification    `json:"bmi_classification"`
 	HealthScore     int                  `json:"health_score"`
+	ScoreBreakdown  []HealthScorePart    `json:"health_score_breakdown"`
+	ScoreHistory    []HealthScorePoint   `json:"health_score_history"` // last 14 days, oldest first
 	TotalRecords    int64                `json:"total_records"`
 	RecentSymptoms  []Symptom            `json:"recent_symptoms"`
-	WeeklyProgress  []HealthData         `json:"weekly_progress"`
+	WeeklyProgress  []HealthDataView     `json:"weekly_progress"`
 	Recommendations []RecommendationItem `json:"recommendations"`
+	Alerts          []HealthAlertView    `json:"alerts"` // open alerts from the last 30 days
 }
 
 type RecommendationItem struct {
@@ -52,15 +78,19 @@ func CalculateBMI(weightKg, heightCm float64) float64 {
 	return weightKg / (heightM * heightM)
 }
 
-func GetBMICategory(bmi float64) string {
-	switch {
-	case bmi < 18.5:
-		return "Underweight"
-	case bmi < 25:
-		return "Normal"
-	case bmi < 30:
-		return "Overweight"
-	default:
-		return "Obese"
+// NewHealthDataView converts a record's weight and height to prefs
+func NewHealthDataView(h HealthData, prefs units.Preferences) HealthDataView {
+	height, heightUnit := units.FromCm(h.HeightCm, prefs.Height)
+	weightUnit := prefs.Weight
+	if weightUnit == "" {
+		weightUnit = units.Kilogram
+	}
+	return HealthDataView{
+		HealthData:    h,
+		Weight:        units.Round(units.FromKg(h.WeightKg, weightUnit), 1),
+		WeightUnit:    weightUnit,
+		Height:        units.Round(height, 1),
+		HeightUnit:    heightUnit,
+		HeightDisplay: units.FormatHeight(h.HeightCm, prefs.Height),
 	}
 }
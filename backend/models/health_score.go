@@ -0,0 +1,309 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Health score component names, also the keys of HEALTH_SCORE_WEIGHTS
+const (
+	ScoreBMI       = "bmi"
+	ScoreSymptoms  = "symptoms"
+	ScoreHydration = "hydration"
+	ScoreActivity  = "activity"
+	ScoreMood      = "mood"
+	ScoreSleep     = "sleep"
+)
+
+// Scoring constants
+const (
+	// symptomHalfLifeDays halves the weight of a symptom every few days
+	symptomHalfLifeDays = 3
+	// symptomBurdenScale is the recency-weighted severity sum that brings
+	// the symptom subscore down to about 37
+	symptomBurdenScale = 20
+	// moodHalfLifeDays weights recent moods more so the score follows the
+	// trend
+	moodHalfLifeDays = 4
+	// weeklyActivityTarget is the WHO recommendation of 150 moderate
+	// minutes a week
+	weeklyActivityTarget = 150
+)
+
+// HealthScoreInput is the data the components look at, as of the end of
+// Day. Symptoms, workouts, water and sleep cover the 7 days up to Day;
+// moods cover 14 days.
+type HealthScoreInput struct {
+	Day           time.Time
+	BMICategory   string // empty when there is no health record or BMI does not apply
+	Symptoms      []Symptom
+	Water         []WaterIntake // completed days only
+	Workouts      []Workout
+	ActivityLevel string // self-reported on the latest health record, used when no workouts were logged
+	Moods         []HealthData
+	Sleep         SleepStats
+}
+
+// HealthScoreComponent scores one aspect of health from 0 to 100. ok is
+// false when there is no data for it; its weight then goes to the other
+// components.
+type HealthScoreComponent interface {
+	Name() string
+	Score(in HealthScoreInput) (score float64, detail string, ok bool)
+}
+
+// HealthScorePart is one component's share of the score. Weight is the
+// configured weight, Share the fraction of the score it ended up with and
+// Points its contribution (Share x Score).
+type HealthScorePart struct {
+	Name      string  `json:"name"`
+	Available bool    `json:"available"`
+	Score     float64 `json:"score"`
+	Weight    float64 `json:"weight"`
+	Share     float64 `json:"share"`
+	Points    float64 `json:"points"`
+	Detail    string  `json:"detail"`
+}
+
+// HealthScore is the overall 0-100 score with its breakdown
+type HealthScore struct {
+	Score      int               `json:"score"`
+	Components []HealthScorePart `json:"components"`
+}
+
+// HealthScorePoint is the score on one day
+type HealthScorePoint struct {
+	Date  string `json:"date"` // YYYY-MM-DD
+	Score int    `json:"score"`
+}
+
+// DefaultHealthScoreComponents are the components the dashboard uses
+var DefaultHealthScoreComponents = []HealthScoreComponent{
+	bmiScore{}, symptomScore{}, hydrationScore{}, activityScore{}, moodScore{}, sleepScore{},
+}
+
+// HealthScoreEngine combines weighted components into one score
+type HealthScoreEngine struct {
+	components []HealthScoreComponent
+	weights    map[string]float64
+}
+
+// NewHealthScoreEngine uses the given components, skipping those without
+// a positive weight
+func NewHealthScoreEngine(weights map[string]float64, components ...HealthScoreComponent) *HealthScoreEngine {
+	e := &HealthScoreEngine{weights: weights}
+	for _, c := range components {
+		if weights[c.Name()] > 0 {
+			e.components = append(e.components, c)
+		}
+	}
+	return e
+}
+
+// Evaluate scores the input. Components without data are listed but do
+// not count; with no data at all the score is 100, as there is nothing
+// wrong to report.
+func (e *HealthScoreEngine) Evaluate(in HealthScoreInput) HealthScore {
+	result := HealthScore{Components: make([]HealthScorePart, 0, len(e.components))}
+
+	var totalWeight float64
+	for _, c := range e.components {
+		part := HealthScorePart{Name: c.Name(), Weight: e.weights[c.Name()]}
+		score, detail, ok := c.Score(in)
+		part.Detail = detail
+		if ok {
+			part.Available = true
+			part.Score = math.Round(math.Max(0, math.Min(100, score))*10) / 10
+			totalWeight += part.Weight
+		}
+		result.Components = append(result.Components, part)
+	}
+
+	if totalWeight == 0 {
+		result.Score = 100
+		return result
+	}
+
+	var total float64
+	for i := range result.Components {
+		part := &result.Components[i]
+		if !part.Available {
+			continue
+		}
+		share := part.Weight / totalWeight
+		total += share * part.Score
+		part.Share = math.Round(share*1000) / 1000
+		part.Points = math.Round(share*part.Score*10) / 10
+	}
+	result.Score = int(math.Round(total))
+	return result
+}
+
+type bmiScore struct{}
+
+func (bmiScore) Name() string { return ScoreBMI }
+
+func (bmiScore) Score(in HealthScoreInput) (float64, string, bool) {
+	switch in.BMICategory {
+	case BMINormal:
+		return 100, "BMI normal", true
+	case BMIOverweight:
+		return 75, "BMI overweight", true
+	case BMIUnderweight:
+		return 65, "BMI underweight", true
+	case BMIObese:
+		return 40, "BMI obesitas", true
+	}
+	return 0, "Belum ada data BMI", false
+}
+
+// symptomScore decays with the severity of recent symptoms, each weighted
+// down by how many days ago it was logged
+type symptomScore struct{}
+
+func (symptomScore) Name() string { return ScoreSymptoms }
+
+func (symptomScore) Score(in HealthScoreInput) (float64, string, bool) {
+	var burden float64
+	for _, s := range in.Symptoms {
+		age := math.Max(0, in.Day.Sub(s.LoggedAt).Hours()/24)
+		burden += float64(s.Severity) * math.Pow(0.5, age/symptomHalfLifeDays)
+	}
+	if len(in.Symptoms) == 0 {
+		return 100, "Tidak ada gejala dalam 7 hari terakhir", true
+	}
+	return 100 * math.Exp(-burden/symptomBurdenScale),
+		fmt.Sprintf("%d gejala dalam 7 hari terakhir, beban keparahan %.1f", len(in.Symptoms), burden), true
+}
+
+// hydrationScore is the average share of the daily water goal reached,
+// each day capped at 100%
+type hydrationScore struct{}
+
+func (hydrationScore) Name() string { return ScoreHydration }
+
+func (hydrationScore) Score(in HealthScoreInput) (float64, string, bool) {
+	var sum float64
+	var days int
+	for _, w := range in.Water {
+		if w.GoalMl <= 0 {
+			continue
+		}
+		sum += math.Min(1, float64(w.AmountMl)/float64(w.GoalMl))
+		days++
+	}
+	if days == 0 {
+		return 0, "Belum ada catatan air minum", false
+	}
+	adherence := sum / float64(days) * 100
+	return adherence, fmt.Sprintf("Rata-rata %.0f%% dari target air minum (%d hari)", adherence, days), true
+}
+
+// activityScore compares weekly workout minutes with the WHO target, with
+// light minutes counting half and vigorous ones double. Without workouts it
+// falls back to the self-reported activity level.
+type activityScore struct{}
+
+func (activityScore) Name() string { return ScoreActivity }
+
+func (activityScore) Score(in HealthScoreInput) (float64, string, bool) {
+	if len(in.Workouts) > 0 {
+		var minutes float64
+		for _, w := range in.Workouts {
+			switch w.Intensity {
+			case IntensityLight:
+				minutes += float64(w.DurationMinutes) / 2
+			case IntensityVigorous:
+				minutes += float64(w.DurationMinutes) * 2
+			default:
+				minutes += float64(w.DurationMinutes)
+			}
+		}
+		return math.Min(1, minutes/weeklyActivityTarget) * 100,
+			fmt.Sprintf("%.0f dari %d menit aktivitas sedang per minggu", minutes, weeklyActivityTarget), true
+	}
+
+	switch in.ActivityLevel {
+	case ActivitySedentary:
+		return 25, "Tingkat aktivitas: sedentary", true
+	case ActivityLight:
+		return 50, "Tingkat aktivitas: light", true
+	case ActivityModerate:
+		return 75, "Tingkat aktivitas: moderate", true
+	case ActivityActive:
+		return 100, "Tingkat aktivitas: active", true
+	}
+	return 0, "Belum ada data aktivitas", false
+}
+
+// moodValues maps emotional states to a 0-100 mood value
+var moodValues = map[string]float64{
+	"happy":    100,
+	"neutral":  75,
+	"stressed": 45,
+	"anxious":  45,
+	"sad":      30,
+}
+
+// moodScore is a recency-weighted average of recent emotional states
+type moodScore struct{}
+
+func (moodScore) Name() string { return ScoreMood }
+
+func (moodScore) Score(in HealthScoreInput) (float64, string, bool) {
+	var sum, weights, recent, earlier float64
+	var recentN, earlierN int
+	for _, h := range in.Moods {
+		value, ok := moodValues[h.EmotionalState]
+		if !ok {
+			continue
+		}
+		age := math.Max(0, in.Day.Sub(h.RecordDate).Hours()/24)
+		w := math.Pow(0.5, age/moodHalfLifeDays)
+		sum += w * value
+		weights += w
+		if age < 7 {
+			recent += value
+			recentN++
+		} else {
+			earlier += value
+			earlierN++
+		}
+	}
+	if weights == 0 {
+		return 0, "Belum ada data suasana hati", false
+	}
+
+	detail := "Suasana hati stabil"
+	if recentN > 0 && earlierN > 0 {
+		switch change := recent/float64(recentN) - earlier/float64(earlierN); {
+		case change >= 10:
+			detail = "Suasana hati membaik dibanding minggu lalu"
+		case change <= -10:
+			detail = "Suasana hati menurun dibanding minggu lalu"
+		}
+	}
+	return sum / weights, detail, true
+}
+
+// sleepScore combines average duration against the target, quality and,
+// from two nights on, regularity
+type sleepScore struct{}
+
+func (sleepScore) Name() string { return ScoreSleep }
+
+func (sleepScore) Score(in HealthScoreInput) (float64, string, bool) {
+	s := in.Sleep
+	if s.NightsLogged == 0 || s.TargetHours <= 0 {
+		return 0, "Belum ada data tidur", false
+	}
+
+	duration := math.Min(1, s.AverageHours/s.TargetHours) * 100
+	quality := (s.AverageQuality - 1) / 4 * 100
+	score := 0.7*duration + 0.3*quality
+	if s.NightsLogged >= 2 {
+		score = 0.6*duration + 0.25*quality + 0.15*float64(s.ConsistencyScore)
+	}
+	return score, fmt.Sprintf("Rata-rata %.1f dari %.1f jam, kualitas %.1f/5", s.AverageHours, s.TargetHours, s.AverageQuality), true
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// LoginAttempt records every sign-in attempt for auditing and lockout
+type LoginAttempt struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	UserID        *uint     `gorm:"index" json:"user_id"` // nil when the email is not registered
+	Email         string    `gorm:"size:255;index" json:"email"`
+	IPAddress     string    `gorm:"size:64;index" json:"ip_address"`
+	UserAgent     string    `gorm:"size:255" json:"user_agent"`
+	Success       bool      `json:"success"`
+	FailureReason string    `gorm:"size:50" json:"failure_reason,omitempty"`
+	CreatedAt     time.Time `gorm:"index" json:"created_at"`
+}
+
+// Login failure reasons
+const (
+	LoginFailureUnknownEmail  = "unknown_email"
+	LoginFailureBadPassword   = "bad_password"
+	LoginFailureBadTwoFactor  = "bad_two_factor_code"
+	LoginFailureAccountLocked = "account_locked"
+	LoginFailureOIDC          = "oidc_rejected"
+)
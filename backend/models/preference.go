@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"health-tracker/units"
+)
+
+// UnitPreference stores the units a user wants to see. Data is always
+// stored in metric; these only affect requests and responses.
+type UnitPreference struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	UserID     uint      `gorm:"uniqueIndex;not null" json:"user_id"`
+	WeightUnit string    `gorm:"size:8;not null;default:kg" json:"weight_unit"`
+	HeightUnit string    `gorm:"size:8;not null;default:cm" json:"height_unit"`
+	VolumeUnit string    `gorm:"size:8;not null;default:ml" json:"volume_unit"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type UnitPreferenceRequest struct {
+	WeightUnit string `json:"weight_unit" binding:"omitempty,oneof=kg lb"`
+	HeightUnit string `json:"height_unit" binding:"omitempty,oneof=cm ft_in"`
+	VolumeUnit string `json:"volume_unit" binding:"omitempty,oneof=ml fl_oz"`
+}
+
+// Units returns the preferences in the form the units package uses
+func (p UnitPreference) Units() units.Preferences {
+	return units.Preferences{Weight: p.WeightUnit, Height: p.HeightUnit, Volume: p.VolumeUnit}
+}
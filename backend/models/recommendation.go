@@ -66,4 +66,6 @@ type DailyMenu struct {
 	AvoidFruits        []string   `json:"avoid_fruits,omitempty"`
 	TotalCalories      string     `json:"total_calories"`
 	TotalEstimatedCost string     `json:"total_estimated_cost,omitempty"` // Total estimasi biaya harian
+	TargetCalories     float64    `json:"target_calories,omitempty"`      // Target energi harian user (kkal)
+	PortionFactor      float64    `json:"portion_factor,omitempty"`       // Pengali porsi resep dasar agar sesuai target
 }
@@ -0,0 +1,27 @@
+package models
+
+// Series is a metric aggregated into time buckets
+type Series struct {
+	Metric        string        `json:"metric"`
+	Unit          string        `json:"unit"`
+	SecondaryName string        `json:"secondary_name,omitempty"` // e.g. diastolic for blood pressure
+	From          string        `json:"from"`
+	To            string        `json:"to"`
+	Bucket        string        `json:"bucket"`      // day, week, month
+	Aggregation   string        `json:"aggregation"` // avg, min, max, last
+	Fill          string        `json:"fill"`        // none, null, previous, linear
+	MovingAverage int           `json:"moving_average,omitempty"`
+	Points        []SeriesPoint `json:"points"`
+}
+
+// SeriesPoint is one bucket. Value is nil for an empty bucket that was
+// not filled; Filled marks values produced by gap filling.
+type SeriesPoint struct {
+	Bucket          string   `json:"bucket"` // first day of the bucket, YYYY-MM-DD (UTC)
+	Value           *float64 `json:"value"`
+	SecondaryValue  *float64 `json:"secondary_value,omitempty"`
+	Count           int      `json:"count"`
+	Filled          bool     `json:"filled,omitempty"`
+	MovingAverage   *float64 `json:"moving_average,omitempty"`
+	SecondaryMoving *float64 `json:"secondary_moving_average,omitempty"`
+}
@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+// SleepSession is one period of sleep, from going to bed until waking up.
+// Naps are logged the same way and count toward the day they end on.
+type SleepSession struct {
+	ID              uint      `gorm:"primaryKey" json:"id"`
+	UserID          uint      `gorm:"not null;index:idx_sleep_user_wake" json:"user_id"`
+	Bedtime         time.Time `gorm:"not null" json:"bedtime"`
+	WakeTime        time.Time `gorm:"not null;index:idx_sleep_user_wake" json:"wake_time"`
+	DurationMinutes int       `json:"duration_minutes"`
+	Quality         int       `json:"quality"` // 1-5
+	Awakenings      int       `json:"awakenings"`
+	Notes           string    `json:"notes"`
+	UTCOffsetMin    int       `json:"utc_offset_minutes"` // offset of the submitted wake_time, for local clock times
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+type SleepSessionRequest struct {
+	Bedtime    string `json:"bedtime" binding:"required"`   // RFC 3339
+	WakeTime   string `json:"wake_time" binding:"required"` // RFC 3339
+	Quality    int    `json:"quality" binding:"required,min=1,max=5"`
+	Awakenings int    `json:"awakenings" binding:"min=0,max=50"`
+	Notes      string `json:"notes"`
+}
+
+// SleepStats summarises sleep over a window of days. Averages are per
+// logged night; nights without a session are not counted as zero sleep.
+type SleepStats struct {
+	From               string  `json:"from"`
+	To                 string  `json:"to"`
+	Days               int     `json:"days"`
+	NightsLogged       int     `json:"nights_logged"`
+	AverageHours       float64 `json:"average_hours"`
+	AverageQuality     float64 `json:"average_quality"`
+	AverageAwakenings  float64 `json:"average_awakenings"`
+	TargetHours        float64 `json:"target_hours"`
+	SleepDebtHours     float64 `json:"sleep_debt_hours"`
+	BedtimeStdDevMin   float64 `json:"bedtime_std_dev_minutes"`
+	WakeTimeStdDevMin  float64 `json:"wake_time_std_dev_minutes"`
+	ConsistencyScore   int     `json:"consistency_score"` // 0-100, higher is more regular
+	AverageBedtime     string  `json:"average_bedtime,omitempty"`
+	AverageWakeTime    string  `json:"average_wake_time,omitempty"`
+	ShortestNightHours float64 `json:"shortest_night_hours"`
+	LongestNightHours  float64 `json:"longest_night_hours"`
+}
+
+// Hours returns the session length in hours
+func (s *SleepSession) Hours() float64 {
+	return float64(s.DurationMinutes) / 60
+}
+
+// Local converts t to the user's clock at the time the session was logged
+func (s *SleepSession) Local(t time.Time) time.Time {
+	return t.In(time.FixedZone("", s.UTCOffsetMin*60))
+}
@@ -4,9 +4,12 @@ import (
 	"time"
 )
 
+// Symptom is one severity reading. Readings that belong to an episode
+// have EpisodeID set and share its name and type.
 type Symptom struct {
 	ID          uint      `gorm:"primaryKey" json:"id"`
 	UserID      uint      `gorm:"not null" json:"user_id"`
+	EpisodeID   *uint     `gorm:"index" json:"episode_id,omitempty"`
 	SymptomType string    `gorm:"not null" json:"symptom_type"` // physical, mental
 	SymptomName string    `gorm:"not null" json:"symptom_name"`
 	Severity    int       `json:"severity"` // 1-10
@@ -19,6 +22,17 @@ type SymptomRequest struct {
 	SymptomName string `json:"symptom_name" binding:"required"`
 	Severity    int    `json:"severity" binding:"required,min=1,max=10"`
 	Notes       string `json:"notes"`
+	LoggedAt    string `json:"logged_at"` // optional, YYYY-MM-DD or RFC 3339; defaults to now
+}
+
+// SymptomUpdateRequest edits a logged symptom. Omitted fields keep their
+// value; name and type of an episode reading are changed on the episode.
+type SymptomUpdateRequest struct {
+	SymptomType *string `json:"symptom_type" binding:"omitempty,oneof=physical mental"`
+	SymptomName *string `json:"symptom_name"`
+	Severity    *int    `json:"severity" binding:"omitempty,min=1,max=10"`
+	Notes       *string `json:"notes"`
+	LoggedAt    string  `json:"logged_at"`
 }
 
 type SymptomTemplate struct {
@@ -28,6 +42,12 @@ type SymptomTemplate struct {
 	Description string `json:"description"`
 }
 
+type SymptomTemplateRequest struct {
+	SymptomType string `json:"symptom_type" binding:"required,oneof=physical mental"`
+	SymptomName string `json:"symptom_name" binding:"required"`
+	Description string `json:"description"`
+}
+
 // Predefined symptoms
 var PhysicalSymptoms = []string{
 	"Demam",
@@ -0,0 +1,134 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Symptom episode statuses
+const (
+	EpisodeActive   = "active"
+	EpisodeResolved = "resolved"
+)
+
+// SymptomEpisode is one occurrence of a symptom from when it started until
+// it was resolved, e.g. a fever lasting four days. Its severity readings
+// are Symptom rows with EpisodeID set.
+type SymptomEpisode struct {
+	ID              uint       `gorm:"primaryKey" json:"id"`
+	UserID          uint       `gorm:"not null;index" json:"user_id"`
+	SymptomType     string     `gorm:"not null" json:"symptom_type"` // physical, mental
+	SymptomName     string     `gorm:"not null" json:"symptom_name"`
+	BodyLocation    string     `json:"body_location"`
+	Triggers        string     `gorm:"type:text" json:"triggers_text"` // one per line, see TriggerList
+	Notes           string     `json:"notes"`
+	StartedAt       time.Time  `gorm:"not null" json:"started_at"`
+	EndedAt         *time.Time `json:"ended_at"`
+	ResolutionNotes string     `json:"resolution_notes"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// SymptomEpisodeRequest starts an episode with its first severity reading
+type SymptomEpisodeRequest struct {
+	SymptomType  string   `json:"symptom_type" binding:"required,oneof=physical mental"`
+	SymptomName  string   `json:"symptom_name" binding:"required"`
+	Severity     int      `json:"severity" binding:"required,min=1,max=10"`
+	BodyLocation string   `json:"body_location"`
+	Triggers     []string `json:"triggers"`
+	Notes        string   `json:"notes"`
+	StartedAt    string   `json:"started_at"` // optional, YYYY-MM-DD or RFC 3339; defaults to now
+}
+
+// SymptomEpisodeUpdateRequest edits an episode. Omitted fields keep their
+// value; ended_at resolves the episode and reopen clears it again.
+type SymptomEpisodeUpdateRequest struct {
+	SymptomType     *string  `json:"symptom_type" binding:"omitempty,oneof=physical mental"`
+	SymptomName     *string  `json:"symptom_name"`
+	BodyLocation    *string  `json:"body_location"`
+	Triggers        []string `json:"triggers"`
+	Notes           *string  `json:"notes"`
+	StartedAt       string   `json:"started_at"`
+	EndedAt         string   `json:"ended_at"`
+	ResolutionNotes *string  `json:"resolution_notes"`
+	Reopen          bool     `json:"reopen"`
+}
+
+// SymptomResolveRequest ends an episode
+type SymptomResolveRequest struct {
+	EndedAt         string `json:"ended_at"` // optional, YYYY-MM-DD or RFC 3339; defaults to now
+	ResolutionNotes string `json:"resolution_notes"`
+}
+
+// SymptomReadingRequest adds a severity reading to an open episode
+type SymptomReadingRequest struct {
+	Severity int    `json:"severity" binding:"required,min=1,max=10"`
+	Notes    string `json:"notes"`
+	LoggedAt string `json:"logged_at"` // optional, YYYY-MM-DD or RFC 3339; defaults to now
+}
+
+// SymptomEpisodeResponse is an episode with a summary of its readings.
+// Readings are only included when a single episode is requested.
+type SymptomEpisodeResponse struct {
+	SymptomEpisode
+	Triggers       []string  `json:"triggers"`
+	Status         string    `json:"status"`
+	DurationDays   int       `json:"duration_days"` // calendar days, counted up to today while active
+	ReadingCount   int       `json:"reading_count"`
+	LatestSeverity int       `json:"latest_severity"`
+	PeakSeverity   int       `json:"peak_severity"`
+	Readings       []Symptom `json:"readings,omitempty"`
+}
+
+// SetTriggers stores the triggers, dropping blank ones
+func (e *SymptomEpisode) SetTriggers(triggers []string) {
+	var kept []string
+	for _, t := range triggers {
+		if t = strings.TrimSpace(t); t != "" {
+			kept = append(kept, t)
+		}
+	}
+	e.Triggers = strings.Join(kept, "\n")
+}
+
+// TriggerList returns the stored triggers
+func (e *SymptomEpisode) TriggerList() []string {
+	list := []string{}
+	for _, t := range strings.Split(e.Triggers, "\n") {
+		if t != "" {
+			list = append(list, t)
+		}
+	}
+	return list
+}
+
+// ToResponse summarises the episode from its readings, oldest first
+func (e *SymptomEpisode) ToResponse(readings []Symptom, now time.Time) SymptomEpisodeResponse {
+	resp := SymptomEpisodeResponse{
+		SymptomEpisode: *e,
+		Triggers:       e.TriggerList(),
+		Status:         EpisodeActive,
+		ReadingCount:   len(readings),
+	}
+
+	end := now
+	if e.EndedAt != nil {
+		resp.Status = EpisodeResolved
+		end = *e.EndedAt
+	}
+	start := e.StartedAt.Local()
+	startDay := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.Local)
+	end = end.Local()
+	endDay := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.Local)
+	resp.DurationDays = int(endDay.Sub(startDay).Hours()/24+0.5) + 1
+
+	for _, r := range readings {
+		if r.Severity > resp.PeakSeverity {
+			resp.PeakSeverity = r.Severity
+		}
+	}
+	if len(readings) > 0 {
+		resp.LatestSeverity = readings[len(readings)-1].Severity
+	}
+	return resp
+}
@@ -10,11 +10,51 @@ type User struct {
 	Password      string    `gorm:"not null" json:"-"`
 	Name          string    `gorm:"not null" json:"name"`
 	BirthDate     time.Time `json:"birth_date"`
+	Sex           string    `gorm:"size:10" json:"sex"` // male, female; used for BMR
 	HeightCm      float64   `json:"height_cm"`
 	WeightKg      float64   `json:"weight_kg"`
 	ActivityLevel string    `gorm:"default:'sedentary'" json:"activity_level"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	EmailVerified bool      `gorm:"default:false" json:"email_verified"`
+	Role          string    `gorm:"size:20;default:'user'" json:"role"`
+
+	TwoFactorEnabled  bool   `gorm:"default:false" json:"two_factor_enabled"`
+	TOTPSecret        string `gorm:"size:64" json:"-"`
+	TOTPPendingSecret string `gorm:"size:64" json:"-"` // secret awaiting confirmation during setup
+	TOTPLastUsedStep  int64  `json:"-"`                // rejects replay of an already used code
+
+	FailedLoginAttempts int        `gorm:"default:0" json:"-"`
+	LockedUntil         *time.Time `json:"-"`
+
+	// Set when the user asked to delete the account; purged after this time
+	// unless they log in again first
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty"`
+
+	// "workouts" once the user logs workouts; ActivityLevel is then derived
+	// from the last 7 days instead of the self-reported value
+	ActivityLevelSource string `gorm:"size:20;default:'self_reported'" json:"activity_level_source"`
+
+	// Consent to email health alerts to approved family members; viewing
+	// them in the app is governed by FamilyMember.CanViewHealth
+	ShareAlertsWithFamily bool `gorm:"default:false" json:"share_alerts_with_family"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// User roles
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+// IsValidRole reports whether role is one of the known roles
+func IsValidRole(role string) bool {
+	switch role {
+	case RoleUser, RoleModerator, RoleAdmin:
+		return true
+	}
+	return false
 }
 
 type RegisterRequest struct {
@@ -29,14 +69,28 @@ type LoginRequest struct {
 }
 
 type LoginResponse struct {
-	Token string `json:"token"`
-	User  User   `json:"user"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"` // access token lifetime in seconds
+	User         User   `json:"user"`
+}
+
+type UpdateRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+type DeleteAccountRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code"` // TOTP or recovery code, required when 2FA is enabled
 }
 
 type UpdateProfileRequest struct {
 	Name          string    `json:"name"`
 	BirthDate     time.Time `json:"birth_date"`
+	Sex           string    `json:"sex" binding:"omitempty,oneof=male female"`
 	HeightCm      float64   `json:"height_cm"`
 	WeightKg      float64   `json:"weight_kg"`
 	ActivityLevel string    `json:"activity_level"`
+
+	ShareAlertsWithFamily *bool `json:"share_alerts_with_family"`
 }
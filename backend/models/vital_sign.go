@@ -0,0 +1,295 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Vital sign types
+const (
+	VitalBloodPressure = "blood_pressure"
+	VitalHeartRate     = "heart_rate"
+	VitalBloodGlucose  = "blood_glucose"
+	VitalTemperature   = "temperature"
+	VitalSpO2          = "spo2"
+	VitalCholesterol   = "cholesterol"
+)
+
+// Blood glucose measurement contexts
+const (
+	GlucoseFasting  = "fasting"
+	GlucosePostMeal = "post_meal" // 2 jam setelah makan
+	GlucoseRandom   = "random"
+)
+
+// VitalSign is a single measurement of one vital sign type. Blood pressure
+// stores systolic in Value and diastolic in SecondaryValue.
+type VitalSign struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	UserID         uint      `gorm:"not null;index:idx_vital_user_type_time" json:"user_id"`
+	Type           string    `gorm:"size:32;not null;index:idx_vital_user_type_time" json:"type"`
+	Value          float64   `gorm:"not null" json:"value"`
+	SecondaryValue *float64  `json:"secondary_value,omitempty"`
+	Unit           string    `gorm:"size:16" json:"unit"`
+	Context        string    `gorm:"size:32" json:"context,omitempty"`
+	Classification string    `gorm:"size:64" json:"classification"`
+	OutOfRange     bool      `json:"out_of_range"`
+	Notes          string    `json:"notes"`
+	MeasuredAt     time.Time `gorm:"not null;index:idx_vital_user_type_time" json:"measured_at"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+type VitalSignRequest struct {
+	Type           string   `json:"type" binding:"required"`
+	Value          float64  `json:"value" binding:"required"`
+	SecondaryValue *float64 `json:"secondary_value"`
+	Context        string   `json:"context"`
+	Notes          string   `json:"notes"`
+	MeasuredAt     string   `json:"measured_at"` // optional, YYYY-MM-DD or RFC 3339; defaults to now
+}
+
+// Range is an inclusive numeric interval
+type Range struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+// Contains reports whether v lies within the range
+func (r Range) Contains(v float64) bool {
+	return v >= r.Min && v <= r.Max
+}
+
+// VitalType describes how a vital sign is measured and classified.
+// Valid bounds reject physiologically implausible input, while Normal
+// is the reference range used for out-of-range flags and graph bands.
+type VitalType struct {
+	Type            string   `json:"type"`
+	Name            string   `json:"name"`
+	Unit            string   `json:"unit"`
+	Valid           Range    `json:"valid"`
+	Normal          Range    `json:"normal"`
+	SecondaryName   string   `json:"secondary_name,omitempty"`
+	SecondaryValid  *Range   `json:"secondary_valid,omitempty"`
+	SecondaryNormal *Range   `json:"secondary_normal,omitempty"`
+	Contexts        []string `json:"contexts,omitempty"`
+	DefaultContext  string   `json:"default_context,omitempty"`
+	Standard        string   `json:"standard,omitempty"`
+
+	classify func(v VitalSign) (string, bool)
+}
+
+// VitalTypes is the registry of supported vital sign types, in display order
+var VitalTypes = []VitalType{
+	{
+		Type:            VitalBloodPressure,
+		Name:            "Tekanan Darah",
+		Unit:            "mmHg",
+		Valid:           Range{Min: 50, Max: 300},
+		Normal:          Range{Min: 90, Max: 119},
+		SecondaryName:   "diastolic",
+		SecondaryValid:  &Range{Min: 30, Max: 200},
+		SecondaryNormal: &Range{Min: 60, Max: 79},
+		Standard:        "AHA/ACC 2017",
+		classify:        classifyBloodPressure,
+	},
+	{
+		Type:     VitalHeartRate,
+		Name:     "Detak Jantung",
+		Unit:     "bpm",
+		Valid:    Range{Min: 20, Max: 250},
+		Normal:   Range{Min: 60, Max: 100},
+		Standard: "Resting heart rate (AHA)",
+		classify: classifyHeartRate,
+	},
+	{
+		Type:           VitalBloodGlucose,
+		Name:           "Gula Darah",
+		Unit:           "mg/dL",
+		Valid:          Range{Min: 20, Max: 800},
+		Normal:         Range{Min: 70, Max: 99},
+		Contexts:       []string{GlucoseFasting, GlucosePostMeal, GlucoseRandom},
+		DefaultContext: GlucoseRandom,
+		Standard:       "ADA Standards of Care",
+		classify:       classifyBloodGlucose,
+	},
+	{
+		Type:     VitalTemperature,
+		Name:     "Suhu Tubuh",
+		Unit:     "°C",
+		Valid:    Range{Min: 30, Max: 45},
+		Normal:   Range{Min: 36.1, Max: 37.5},
+		classify: classifyTemperature,
+	},
+	{
+		Type:     VitalSpO2,
+		Name:     "Saturasi Oksigen",
+		Unit:     "%",
+		Valid:    Range{Min: 50, Max: 100},
+		Normal:   Range{Min: 95, Max: 100},
+		classify: classifySpO2,
+	},
+	{
+		Type:     VitalCholesterol,
+		Name:     "Kolesterol Total",
+		Unit:     "mg/dL",
+		Valid:    Range{Min: 50, Max: 1000},
+		Normal:   Range{Min: 0, Max: 199},
+		Standard: "NCEP ATP III",
+		classify: classifyCholesterol,
+	},
+}
+
+// GetVitalType looks up a vital sign type in the registry
+func GetVitalType(t string) (VitalType, bool) {
+	for _, vt := range VitalTypes {
+		if vt.Type == t {
+			return vt, true
+		}
+	}
+	return VitalType{}, false
+}
+
+// HasContext reports whether ctx is a valid measurement context for the type
+func (vt VitalType) HasContext(ctx string) bool {
+	for _, c := range vt.Contexts {
+		if c == ctx {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks a measurement against the type's plausible bounds
+func (vt VitalType) Validate(v VitalSign) error {
+	if !vt.Valid.Contains(v.Value) {
+		return fmt.Errorf("value must be between %g and %g %s", vt.Valid.Min, vt.Valid.Max, vt.Unit)
+	}
+	if vt.SecondaryValid != nil {
+		if v.SecondaryValue == nil {
+			return fmt.Errorf("secondary_value (%s) is required", vt.SecondaryName)
+		}
+		if !vt.SecondaryValid.Contains(*v.SecondaryValue) {
+			return fmt.Errorf("%s must be between %g and %g %s", vt.SecondaryName, vt.SecondaryValid.Min, vt.SecondaryValid.Max, vt.Unit)
+		}
+		if *v.SecondaryValue >= v.Value {
+			return fmt.Errorf("%s must be lower than the systolic value", vt.SecondaryName)
+		}
+	}
+	if len(vt.Contexts) > 0 && !vt.HasContext(v.Context) {
+		return fmt.Errorf("context must be one of %v", vt.Contexts)
+	}
+	return nil
+}
+
+// Classify returns the clinical category of a measurement and whether it
+// falls outside the normal range
+func (vt VitalType) Classify(v VitalSign) (string, bool) {
+	return vt.classify(v)
+}
+
+// classifyBloodPressure follows the AHA/ACC 2017 categories. The higher
+// of the systolic and diastolic categories wins.
+func classifyBloodPressure(v VitalSign) (string, bool) {
+	sys := v.Value
+	var dia float64
+	if v.SecondaryValue != nil {
+		dia = *v.SecondaryValue
+	}
+
+	switch {
+	case sys > 180 || dia > 120:
+		return "Hypertensive Crisis", true
+	case sys >= 140 || dia >= 90:
+		return "Hypertension Stage 2", true
+	case sys >= 130 || dia >= 80:
+		return "Hypertension Stage 1", true
+	case sys < 90 || dia < 60:
+		return "Low", true
+	case sys >= 120:
+		return "Elevated", true
+	default:
+		return "Normal", false
+	}
+}
+
+func classifyHeartRate(v VitalSign) (string, bool) {
+	switch {
+	case v.Value < 60:
+		return "Bradycardia", true
+	case v.Value > 100:
+		return "Tachycardia", true
+	default:
+		return "Normal", false
+	}
+}
+
+// classifyBloodGlucose uses ADA diagnostic thresholds for fasting and
+// 2-hour post-meal readings. Random readings are only flagged at the
+// diabetes threshold of 200 mg/dL.
+func classifyBloodGlucose(v VitalSign) (string, bool) {
+	if v.Value < 70 {
+		return "Hypoglycemia", true
+	}
+
+	switch v.Context {
+	case GlucoseFasting:
+		switch {
+		case v.Value >= 126:
+			return "Diabetes Range", true
+		case v.Value >= 100:
+			return "Prediabetes", true
+		}
+	case GlucosePostMeal:
+		switch {
+		case v.Value >= 200:
+			return "Diabetes Range", true
+		case v.Value >= 140:
+			return "Prediabetes", true
+		}
+	default:
+		if v.Value >= 200 {
+			return "Diabetes Range", true
+		}
+	}
+	return "Normal", false
+}
+
+func classifyTemperature(v VitalSign) (string, bool) {
+	switch {
+	case v.Value < 35:
+		return "Hypothermia", true
+	case v.Value < 36.1:
+		return "Low", true
+	case v.Value <= 37.5:
+		return "Normal", false
+	case v.Value < 38:
+		return "Low-grade Fever", true
+	case v.Value < 39.5:
+		return "Fever", true
+	default:
+		return "High Fever", true
+	}
+}
+
+func classifySpO2(v VitalSign) (string, bool) {
+	switch {
+	case v.Value >= 95:
+		return "Normal", false
+	case v.Value > 90:
+		return "Low", true
+	default:
+		return "Hypoxemia", true
+	}
+}
+
+func classifyCholesterol(v VitalSign) (string, bool) {
+	switch {
+	case v.Value >= 240:
+		return "High", true
+	case v.Value >= 200:
+		return "Borderline High", true
+	default:
+		return "Desirable", false
+	}
+}
@@ -1,45 +1,79 @@
 package models
 
-import "time"
+import (
+	"math"
+	"time"
 
-// WaterIntake represents daily water intake tracking
+	"health-tracker/units"
+)
+
+// WaterIntake represents daily water intake tracking. AmountMl and GoalMl
+// are the stored values; Glasses and Goal mirror them in 250 ml glasses
+// for older clients.
 type WaterIntake struct {
 	ID        uint      `json:"id" gorm:"primaryKey"`
 	UserID    uint      `json:"user_id" gorm:"not null"`
 	Glasses   int       `json:"glasses" gorm:"default:0"` // Number of glasses (1 glass = 250ml)
 	Goal      int       `json:"goal" gorm:"default:8"`    // Daily goal in glasses
+	AmountMl  int       `json:"amount_ml" gorm:"default:0"`
+	GoalMl    int       `json:"goal_ml" gorm:"default:2000"`
 	Date      string    `json:"date" gorm:"size:10;not null"` // Format: YYYY-MM-DD
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
-// WaterIntakeResponse is the response structure for water intake
+// WaterIntakeResponse is the response structure for water intake. The
+// amounts are in Unit, the user's preferred volume unit.
 type WaterIntakeResponse struct {
-	ID         uint    `json:"id"`
-	Glasses    int     `json:"glasses"`
-	Goal       int     `json:"goal"`
-	Date       string  `json:"date"`
-	Percentage float64 `json:"percentage"`
-	Remaining  int     `json:"remaining"`
+	ID              uint    `json:"id"`
+	Glasses         int     `json:"glasses"`
+	Goal            int     `json:"goal"`
+	Date            string  `json:"date"`
+	Percentage      float64 `json:"percentage"`
+	Remaining       int     `json:"remaining"`
+	Amount          float64 `json:"amount"`
+	GoalAmount      float64 `json:"goal_amount"`
+	RemainingAmount float64 `json:"remaining_amount"`
+	Unit            string  `json:"unit"`
+}
+
+// SetAmountMl updates the intake and the matching glass count
+func (w *WaterIntake) SetAmountMl(ml int) {
+	if ml < 0 {
+		ml = 0
+	}
+	w.AmountMl = ml
+	w.Glasses = int(math.Round(float64(ml) / units.MlPerGlass))
+}
+
+// SetGoalMl updates the goal and the matching glass count
+func (w *WaterIntake) SetGoalMl(ml int) {
+	w.GoalMl = ml
+	w.Goal = int(math.Round(float64(ml) / units.MlPerGlass))
 }
 
 // GetPercentage calculates the percentage of goal achieved
 func (w *WaterIntake) GetPercentage() float64 {
-	if w.Goal == 0 {
+	if w.GoalMl == 0 {
 		return 0
 	}
-	percentage := float64(w.Glasses) / float64(w.Goal) * 100
+	percentage := float64(w.AmountMl) / float64(w.GoalMl) * 100
 	if percentage > 100 {
 		return 100
 	}
 	return percentage
 }
 
-// GetRemaining calculates remaining glasses to reach goal
-func (w *WaterIntake) GetRemaining() int {
-	remaining := w.Goal - w.Glasses
+// GetRemainingMl calculates the volume still needed to reach the goal
+func (w *WaterIntake) GetRemainingMl() int {
+	remaining := w.GoalMl - w.AmountMl
 	if remaining < 0 {
 		return 0
 	}
 	return remaining
 }
+
+// GetRemaining calculates remaining glasses to reach goal
+func (w *WaterIntake) GetRemaining() int {
+	return int(math.Ceil(float64(w.GetRemainingMl()) / units.MlPerGlass))
+}
@@ -0,0 +1,146 @@
+package models
+
+import "time"
+
+// Workout intensities
+const (
+	IntensityLight    = "light"
+	IntensityModerate = "moderate"
+	IntensityVigorous = "vigorous"
+)
+
+// Activity levels, shared with the self-reported profile field
+const (
+	ActivitySedentary = "sedentary"
+	ActivityLight     = "light"
+	ActivityModerate  = "moderate"
+	ActivityActive    = "active"
+)
+
+// Where User.ActivityLevel comes from
+const (
+	ActivitySourceSelfReported = "self_reported"
+	ActivitySourceWorkouts     = "workouts"
+)
+
+// Workout is one logged exercise session. MET and WeightKg are kept so
+// the calorie estimate stays reproducible after the user's weight changes.
+type Workout struct {
+	ID              uint      `gorm:"primaryKey" json:"id"`
+	UserID          uint      `gorm:"not null;index:idx_workout_user_time" json:"user_id"`
+	Type            string    `gorm:"size:32;not null" json:"type"`
+	DurationMinutes int       `gorm:"not null" json:"duration_minutes"`
+	Intensity       string    `gorm:"size:16;not null" json:"intensity"`
+	DistanceKm      *float64  `json:"distance_km,omitempty"`
+	Steps           *int      `json:"steps,omitempty"`
+	MET             float64   `json:"met"`
+	WeightKg        float64   `json:"weight_kg"`
+	CaloriesBurned  float64   `json:"calories_burned"`
+	Notes           string    `json:"notes"`
+	PerformedAt     time.Time `gorm:"not null;index:idx_workout_user_time" json:"performed_at"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+type WorkoutRequest struct {
+	Type            string   `json:"type" binding:"required"`
+	DurationMinutes int      `json:"duration_minutes" binding:"required,min=1,max=1440"`
+	Intensity       string   `json:"intensity" binding:"omitempty,oneof=light moderate vigorous"`
+	DistanceKm      *float64 `json:"distance_km" binding:"omitempty,gt=0,lte=1000"`
+	Steps           *int     `json:"steps" binding:"omitempty,min=0,max=200000"`
+	Notes           string   `json:"notes"`
+	PerformedAt     string   `json:"performed_at"` // optional, YYYY-MM-DD or RFC 3339; defaults to now
+
+	// On update, estimate calories from the current weight instead of the
+	// weight stored with the workout
+	RecalculateWeight bool `json:"recalculate_weight"`
+}
+
+// WorkoutSummary totals workouts over a window of days
+type WorkoutSummary struct {
+	From           string  `json:"from"`
+	To             string  `json:"to"`
+	Days           int     `json:"days"`
+	Sessions       int     `json:"sessions"`
+	ActiveDays     int     `json:"active_days"`
+	TotalMinutes   int     `json:"total_minutes"`
+	TotalCalories  float64 `json:"total_calories"`
+	TotalDistance  float64 `json:"total_distance_km"`
+	TotalSteps     int     `json:"total_steps"`
+	METMinutes     float64 `json:"met_minutes"`
+	ActivityLevel  string  `json:"activity_level"`
+	MeetsWHOTarget bool    `json:"meets_who_target"` // >= 600 MET-minutes per week
+}
+
+// SpeedMET maps a speed band (up to MaxKmh) to a MET value. The last
+// band has MaxKmh 0 and covers everything faster.
+type SpeedMET struct {
+	MaxKmh float64 `json:"max_kmh"`
+	MET    float64 `json:"met"`
+}
+
+// WorkoutType is an entry in the MET table. Values are taken from the
+// 2011 Compendium of Physical Activities.
+type WorkoutType struct {
+	Type     string             `json:"type"`
+	Name     string             `json:"name"`
+	METs     map[string]float64 `json:"mets"`               // by intensity
+	BySpeed  []SpeedMET         `json:"by_speed,omitempty"` // used instead when distance is logged
+	Distance bool               `json:"distance"`
+}
+
+// WorkoutTypes is the built-in MET table
+var WorkoutTypes = []WorkoutType{
+	{Type: "walking", Name: "Jalan Kaki", Distance: true,
+		METs:    map[string]float64{IntensityLight: 2.8, IntensityModerate: 3.5, IntensityVigorous: 5.0},
+		BySpeed: []SpeedMET{{3.2, 2.8}, {4.8, 3.5}, {5.6, 4.3}, {6.4, 5.0}, {0, 7.0}}},
+	{Type: "running", Name: "Lari", Distance: true,
+		METs:    map[string]float64{IntensityLight: 7.0, IntensityModerate: 9.8, IntensityVigorous: 11.5},
+		BySpeed: []SpeedMET{{8.0, 8.3}, {9.7, 9.8}, {11.3, 11.0}, {12.9, 11.8}, {14.5, 12.8}, {0, 14.5}}},
+	{Type: "cycling", Name: "Bersepeda", Distance: true,
+		METs:    map[string]float64{IntensityLight: 4.0, IntensityModerate: 6.8, IntensityVigorous: 10.0},
+		BySpeed: []SpeedMET{{16.1, 4.0}, {19.2, 6.8}, {22.4, 8.0}, {25.6, 10.0}, {0, 12.0}}},
+	{Type: "swimming", Name: "Berenang", Distance: true,
+		METs: map[string]float64{IntensityLight: 6.0, IntensityModerate: 8.3, IntensityVigorous: 9.8}},
+	{Type: "hiking", Name: "Hiking", Distance: true,
+		METs: map[string]float64{IntensityLight: 5.3, IntensityModerate: 6.0, IntensityVigorous: 7.8}},
+	{Type: "strength", Name: "Latihan Beban", METs: map[string]float64{IntensityLight: 3.5, IntensityModerate: 5.0, IntensityVigorous: 6.0}},
+	{Type: "hiit", Name: "HIIT / Sirkuit", METs: map[string]float64{IntensityLight: 4.3, IntensityModerate: 8.0, IntensityVigorous: 10.0}},
+	{Type: "aerobics", Name: "Senam Aerobik", METs: map[string]float64{IntensityLight: 5.0, IntensityModerate: 6.5, IntensityVigorous: 7.3}},
+	{Type: "yoga", Name: "Yoga", METs: map[string]float64{IntensityLight: 2.5, IntensityModerate: 3.0, IntensityVigorous: 4.0}},
+	{Type: "pilates", Name: "Pilates", METs: map[string]float64{IntensityLight: 3.0, IntensityModerate: 3.5, IntensityVigorous: 4.5}},
+	{Type: "dancing", Name: "Menari / Zumba", METs: map[string]float64{IntensityLight: 3.0, IntensityModerate: 5.0, IntensityVigorous: 7.3}},
+	{Type: "badminton", Name: "Bulu Tangkis", METs: map[string]float64{IntensityLight: 4.5, IntensityModerate: 5.5, IntensityVigorous: 7.0}},
+	{Type: "football", Name: "Sepak Bola / Futsal", METs: map[string]float64{IntensityLight: 5.0, IntensityModerate: 7.0, IntensityVigorous: 10.0}},
+	{Type: "basketball", Name: "Bola Basket", METs: map[string]float64{IntensityLight: 4.5, IntensityModerate: 6.5, IntensityVigorous: 8.0}},
+	{Type: "other", Name: "Lainnya", METs: map[string]float64{IntensityLight: 3.0, IntensityModerate: 4.5, IntensityVigorous: 6.0}},
+}
+
+// GetWorkoutType looks up a workout type in the MET table
+func GetWorkoutType(t string) (WorkoutType, bool) {
+	for _, wt := range WorkoutTypes {
+		if wt.Type == t {
+			return wt, true
+		}
+	}
+	return WorkoutType{}, false
+}
+
+// EstimateMET picks the MET value for a session, preferring the speed
+// table when a distance was logged
+func (wt WorkoutType) EstimateMET(intensity string, durationMinutes int, distanceKm *float64) float64 {
+	if distanceKm != nil && len(wt.BySpeed) > 0 && durationMinutes > 0 {
+		speed := *distanceKm / (float64(durationMinutes) / 60)
+		for _, band := range wt.BySpeed {
+			if band.MaxKmh == 0 || speed <= band.MaxKmh {
+				return band.MET
+			}
+		}
+	}
+	return wt.METs[intensity]
+}
+
+// CaloriesBurned estimates energy use as MET x body weight (kg) x hours
+func CaloriesBurned(met, weightKg float64, durationMinutes int) float64 {
+	return met * weightKg * float64(durationMinutes) / 60
+}
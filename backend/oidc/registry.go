@@ -0,0 +1,29 @@
+package oidc
+
+import "sort"
+
+var providers = map[string]*Provider{}
+
+// InitProviders registers the configured identity providers
+func InitProviders(configs []Config) {
+	providers = make(map[string]*Provider, len(configs))
+	for _, cfg := range configs {
+		providers[cfg.Name] = NewProvider(cfg)
+	}
+}
+
+// GetProvider returns a registered provider by name
+func GetProvider(name string) (*Provider, bool) {
+	p, ok := providers[name]
+	return p, ok
+}
+
+// ProviderNames lists the registered providers in a stable order
+func ProviderNames() []string {
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
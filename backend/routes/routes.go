@@ -5,6 +5,7 @@ import (
 
 	"health-tracker/handlers"
 	"health-tracker/middleware"
+	"health-tracker/models"
 
 	"github.com/gin-gonic/gin"
 )
@@ -25,7 +26,15 @@ func SetupRoutes(r *gin.Engine) {
 		{
 			auth.POST("/register", handlers.Register)
 			auth.POST("/login", handlers.Login)
+			auth.POST("/forgot-password", handlers.ForgotPassword)
 			auth.POST("/reset-password", handlers.ResetPassword)
+			auth.POST("/refresh", handlers.RefreshAccessToken)
+			auth.GET("/verify", handlers.VerifyEmail)
+			auth.POST("/resend-verification", handlers.ResendVerificationByEmail)
+			auth.POST("/2fa/verify", handlers.VerifyTwoFactorLogin)
+			auth.GET("/oidc/providers", handlers.GetOIDCProviders)
+			auth.GET("/oidc/:provider/authorize", handlers.OIDCAuthorize)
+			auth.POST("/oidc/:provider/callback", handlers.OIDCCallback)
 		}
 
 		// Articles routes (public)
@@ -37,36 +46,142 @@ func SetupRoutes(r *gin.Engine) {
 			articles.GET("/:id", handlers.GetArticle)
 		}
 
-		// Protected routes (auth required)
+		// Protected routes (auth required, signed-in sessions only)
 		protected := api.Group("")
-		protected.Use(middleware.AuthMiddleware())
+		protected.Use(middleware.AuthMiddleware(), middleware.RequireSession())
 		{
 			// User routes
 			protected.GET("/auth/me", handlers.GetCurrentUser)
 			protected.PUT("/auth/profile", handlers.UpdateProfile)
+			protected.GET("/auth/preferences", handlers.GetUnitPreferences)
+			protected.PUT("/auth/preferences", handlers.UpdateUnitPreferences)
+			protected.POST("/auth/logout", handlers.Logout)
+			protected.GET("/auth/login-history", handlers.GetLoginHistory)
+			protected.GET("/auth/sessions", handlers.GetSessions)
+			protected.DELETE("/auth/sessions/:id", handlers.RevokeSession)
+			protected.POST("/auth/verify/resend", handlers.ResendVerification)
+			protected.GET("/auth/identities", handlers.GetLinkedIdentities)
 
+			// Two-factor authentication routes
+			twoFactor := protected.Group("/auth/2fa")
+			{
+				twoFactor.POST("/setup", handlers.SetupTwoFactor)
+				twoFactor.POST("/enable", handlers.EnableTwoFactor)
+				twoFactor.POST("/disable", handlers.DisableTwoFactor)
+				twoFactor.POST("/recovery-codes", handlers.RegenerateRecoveryCodes)
+			}
+
+			// Personal API key routes
+			apiKeys := protected.Group("/auth/api-keys")
+			{
+				apiKeys.GET("", handlers.GetAPIKeys)
+				apiKeys.POST("", handlers.CreateAPIKey)
+				apiKeys.DELETE("/:id", handlers.RevokeAPIKey)
+			}
+
+			// Account data routes
+			account := protected.Group("/account")
+			{
+				account.DELETE("", handlers.DeleteAccount)
+				account.GET("/export", handlers.ExportAccount)
+				account.GET("/exports", handlers.GetExports)
+				account.GET("/exports/:id/download", handlers.DownloadExport)
+				account.GET("/audit", handlers.GetAccountAuditLog)
+			}
+		}
+
+		// Emailed export download link, the token is the credential
+		api.GET("/account/export/download", handlers.DownloadExportByToken)
+
+		// Data routes, also open to personal API keys holding the group's scope
+		scoped := api.Group("")
+		scoped.Use(middleware.AuthMiddleware())
+		{
 			// Health data routes
-			health := protected.Group("/health")
+			health := scoped.Group("/health", middleware.RequireScope("health"))
 			{
 				health.POST("", handlers.CreateHealthData)
 				health.GET("", handlers.GetHealthData)
 				health.GET("/latest", handlers.GetLatestHealthData)
 				health.GET("/dashboard", handlers.GetDashboard)
 				health.GET("/graph/:period", handlers.GetHealthGraph)
+				health.GET("/series", handlers.GetHealthSeries)
+				health.GET("/energy", handlers.GetEnergyTargets)
+				health.GET("/forecast", handlers.GetWeightForecast)
+				health.GET("/score", handlers.GetHealthScore)
+				health.GET("/alerts", handlers.GetHealthAlerts)
+				health.POST("/alerts/check", handlers.CheckHealthAlerts)
+				health.PUT("/alerts/:id/acknowledge", handlers.AcknowledgeHealthAlert)
+				health.PUT("/:id", handlers.UpdateHealthData)
+				health.DELETE("/:id", handlers.DeleteHealthData)
 			}
 
 			// Symptom routes
-			symptoms := protected.Group("/symptoms")
+			symptoms := scoped.Group("/symptoms", middleware.RequireScope("symptoms"))
 			{
 				symptoms.GET("/list", handlers.GetSymptomList)
 				symptoms.POST("", handlers.LogSymptom)
 				symptoms.POST("/batch", handlers.LogMultipleSymptoms)
 				symptoms.GET("/history", handlers.GetSymptomHistory)
 				symptoms.GET("/stats", handlers.GetSymptomStats)
+				symptoms.PUT("/:id", handlers.UpdateSymptom)
+				symptoms.DELETE("/:id", handlers.DeleteSymptom)
+				symptoms.POST("/episodes", handlers.CreateSymptomEpisode)
+				symptoms.GET("/episodes", handlers.GetSymptomEpisodes)
+				symptoms.GET("/episodes/:id", handlers.GetSymptomEpisode)
+				symptoms.PUT("/episodes/:id", handlers.UpdateSymptomEpisode)
+				symptoms.DELETE("/episodes/:id", handlers.DeleteSymptomEpisode)
+				symptoms.POST("/episodes/:id/resolve", handlers.ResolveSymptomEpisode)
+				symptoms.POST("/episodes/:id/readings", handlers.AddSymptomReading)
+			}
+
+			// Vital sign routes
+			vitals := scoped.Group("/vitals", middleware.RequireScope("vitals"))
+			{
+				vitals.GET("/types", handlers.GetVitalTypes)
+				vitals.POST("", handlers.CreateVitalSign)
+				vitals.GET("", handlers.GetVitalSigns)
+				vitals.GET("/latest", handlers.GetLatestVitalSigns)
+				vitals.GET("/graph/:period", handlers.GetVitalSignGraph)
+				vitals.PUT("/:id", handlers.UpdateVitalSign)
+				vitals.DELETE("/:id", handlers.DeleteVitalSign)
+			}
+
+			// Sleep routes
+			sleep := scoped.Group("/sleep", middleware.RequireScope("sleep"))
+			{
+				sleep.POST("", handlers.LogSleep)
+				sleep.GET("", handlers.GetSleepHistory)
+				sleep.GET("/stats", handlers.GetSleepStats)
+				sleep.PUT("/:id", handlers.UpdateSleep)
+				sleep.DELETE("/:id", handlers.DeleteSleep)
+			}
+
+			// Workout routes
+			workouts := scoped.Group("/workouts", middleware.RequireScope("workouts"))
+			{
+				workouts.GET("/types", handlers.GetWorkoutTypes)
+				workouts.POST("", handlers.LogWorkout)
+				workouts.GET("", handlers.GetWorkouts)
+				workouts.GET("/summary", handlers.GetWorkoutSummary)
+				workouts.PUT("/:id", handlers.UpdateWorkout)
+				workouts.DELETE("/:id", handlers.DeleteWorkout)
+			}
+
+			// Nutrition routes
+			nutrition := scoped.Group("/nutrition", middleware.RequireScope("nutrition"))
+			{
+				nutrition.GET("/foods", handlers.SearchFoods)
+				nutrition.GET("/foods/:id", handlers.GetFood)
+				nutrition.POST("/meals", handlers.LogMeal)
+				nutrition.GET("/meals", handlers.GetMeals)
+				nutrition.PUT("/meals/:id", handlers.UpdateMeal)
+				nutrition.DELETE("/meals/:id", handlers.DeleteMeal)
+				nutrition.GET("/daily", handlers.GetDailyNutrition)
 			}
 
 			// Family routes
-			family := protected.Group("/family")
+			family := scoped.Group("/family", middleware.RequireScope("family"))
 			{
 				family.POST("/invite", handlers.InviteFamilyMember)
 				family.GET("/members", handlers.GetFamilyMembers)
@@ -78,7 +193,7 @@ func SetupRoutes(r *gin.Engine) {
 			}
 
 			// Recommendation routes
-			recommendations := protected.Group("/recommendations")
+			recommendations := scoped.Group("/recommendations", middleware.RequireScope("recommendations"))
 			{
 				recommendations.GET("/food", handlers.GetFoodRecommendations)
 				recommendations.GET("/exercise", handlers.GetExerciseRecommendations)
@@ -87,18 +202,18 @@ func SetupRoutes(r *gin.Engine) {
 			}
 
 			// Forum routes
-			forum := protected.Group("/forum")
+			forum := scoped.Group("/forum", middleware.RequireScope("forum"))
 			{
 				forum.GET("/posts", handlers.GetPosts)
-				forum.POST("/posts", handlers.CreatePost)
+				forum.POST("/posts", middleware.RequireVerifiedEmail(), handlers.CreatePost)
 				forum.GET("/posts/:id", handlers.GetPost)
 				forum.DELETE("/posts/:id", handlers.DeletePost)
-				forum.POST("/posts/:id/comments", handlers.AddComment)
+				forum.POST("/posts/:id/comments", middleware.RequireVerifiedEmail(), handlers.AddComment)
 				forum.POST("/posts/:id/like", handlers.ToggleLike)
 			}
 
 			// Water tracker routes
-			water := protected.Group("/water")
+			water := scoped.Group("/water", middleware.RequireScope("water"))
 			{
 				water.GET("", handlers.GetWaterIntake)
 				water.POST("/add", handlers.AddWaterGlass)
@@ -108,7 +223,7 @@ func SetupRoutes(r *gin.Engine) {
 			}
 
 			// Goals routes
-			goals := protected.Group("/goals")
+			goals := scoped.Group("/goals", middleware.RequireScope("goals"))
 			{
 				goals.GET("", handlers.GetGoals)
 				goals.POST("", handlers.CreateGoal)
@@ -119,7 +234,7 @@ func SetupRoutes(r *gin.Engine) {
 			}
 
 			// Reminders routes
-			reminders := protected.Group("/reminders")
+			reminders := scoped.Group("/reminders", middleware.RequireScope("reminders"))
 			{
 				reminders.GET("", handlers.GetReminders)
 				reminders.POST("", handlers.CreateReminder)
@@ -128,8 +243,35 @@ func SetupRoutes(r *gin.Engine) {
 				reminders.PUT("/:id/toggle", handlers.ToggleReminder)
 			}
 		}
+
+		// Admin routes (moderators can only moderate the forum)
+		admin := protected.Group("/admin")
+		admin.Use(middleware.RequireRole(models.RoleModerator, models.RoleAdmin))
+		{
+			admin.DELETE("/forum/posts/:id", handlers.ModerateDeletePost)
+			admin.DELETE("/forum/comments/:id", handlers.ModerateDeleteComment)
+
+			adminOnly := admin.Group("")
+			adminOnly.Use(middleware.RequireRole(models.RoleAdmin))
+			{
+				adminOnly.GET("/users", handlers.AdminListUsers)
+				adminOnly.PUT("/users/:id/role", handlers.AdminUpdateUserRole)
+				adminOnly.GET("/audit", handlers.AdminGetAuditLog)
+
+				adminOnly.POST("/articles", handlers.AdminCreateArticle)
+				adminOnly.PUT("/articles/:id", handlers.AdminUpdateArticle)
+				adminOnly.DELETE("/articles/:id", handlers.AdminDeleteArticle)
+
+				adminOnly.POST("/symptom-templates", handlers.AdminCreateSymptomTemplate)
+				adminOnly.PUT("/symptom-templates/:id", handlers.AdminUpdateSymptomTemplate)
+				adminOnly.DELETE("/symptom-templates/:id", handlers.AdminDeleteSymptomTemplate)
+			}
+		}
 	}
 
+	// Public keys for verifying our JWTs
+	r.GET("/.well-known/jwks.json", handlers.GetJWKS)
+
 	// Health check
 	r.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{"status": "ok", "message": "Health Tracker API is running"})
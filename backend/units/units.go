@@ -0,0 +1,128 @@
+// Package units converts weights, heights and volumes between metric and
+// imperial. Everything is stored in kg, cm and ml; conversion only happens
+// when reading requests and writing responses.
+package units
+
+import (
+	"fmt"
+	"math"
+)
+
+// Unit names accepted in requests and preferences
+const (
+	Kilogram   = "kg"
+	Pound      = "lb"
+	Centimeter = "cm"
+	Inch       = "in"
+	FeetInches = "ft_in" // shown as feet and inches, numeric values in inches
+	Milliliter = "ml"
+	FluidOunce = "fl_oz" // US fluid ounce
+	Glass      = "glass"
+)
+
+// Conversion factors to the metric storage units
+const (
+	KgPerPound      = 0.45359237
+	CmPerInch       = 2.54
+	MlPerFluidOunce = 29.5735295625
+	MlPerGlass      = 250
+)
+
+// Preferences are the units a user wants to see
+type Preferences struct {
+	Weight string `json:"weight"` // kg or lb
+	Height string `json:"height"` // cm or ft_in
+	Volume string `json:"volume"` // ml or fl_oz
+}
+
+// Metric is the default for users without saved preferences
+var Metric = Preferences{Weight: Kilogram, Height: Centimeter, Volume: Milliliter}
+
+// ToKg converts a weight to kilograms
+func ToKg(value float64, unit string) (float64, error) {
+	switch unit {
+	case Kilogram, "":
+		return value, nil
+	case Pound:
+		return value * KgPerPound, nil
+	}
+	return 0, fmt.Errorf("unknown weight unit %q, use kg or lb", unit)
+}
+
+// FromKg converts kilograms to the given weight unit
+func FromKg(kg float64, unit string) float64 {
+	if unit == Pound {
+		return kg / KgPerPound
+	}
+	return kg
+}
+
+// ToCm converts a height to centimetres. Feet-and-inches values are
+// given in inches.
+func ToCm(value float64, unit string) (float64, error) {
+	switch unit {
+	case Centimeter, "":
+		return value, nil
+	case Inch, FeetInches:
+		return value * CmPerInch, nil
+	}
+	return 0, fmt.Errorf("unknown height unit %q, use cm, in or ft_in", unit)
+}
+
+// FromCm converts centimetres to the given height unit and returns the
+// unit the number is in
+func FromCm(cm float64, unit string) (float64, string) {
+	if unit == Inch || unit == FeetInches {
+		return cm / CmPerInch, Inch
+	}
+	return cm, Centimeter
+}
+
+// FormatHeight renders a height for display, e.g. "175 cm" or "5 ft 9 in"
+func FormatHeight(cm float64, unit string) string {
+	if unit != Inch && unit != FeetInches {
+		return fmt.Sprintf("%.0f cm", cm)
+	}
+	inches := math.Round(cm / CmPerInch)
+	return fmt.Sprintf("%.0f ft %.0f in", math.Floor(inches/12), math.Mod(inches, 12))
+}
+
+// ToMl converts a volume to millilitres
+func ToMl(value float64, unit string) (float64, error) {
+	switch unit {
+	case Milliliter, "":
+		return value, nil
+	case FluidOunce:
+		return value * MlPerFluidOunce, nil
+	case Glass, "glasses":
+		return value * MlPerGlass, nil
+	}
+	return 0, fmt.Errorf("unknown volume unit %q, use ml, fl_oz or glass", unit)
+}
+
+// FromMl converts millilitres to the given volume unit
+func FromMl(ml float64, unit string) float64 {
+	switch unit {
+	case FluidOunce:
+		return ml / MlPerFluidOunce
+	case Glass:
+		return ml / MlPerGlass
+	}
+	return ml
+}
+
+// IsWeight reports whether unit is a weight unit
+func IsWeight(unit string) bool {
+	return unit == Kilogram || unit == Pound
+}
+
+// IsVolume reports whether unit is a volume unit
+func IsVolume(unit string) bool {
+	return unit == Milliliter || unit == FluidOunce || unit == Glass || unit == "glasses"
+}
+
+// Round rounds to the given number of decimals
+func Round(value float64, decimals int) float64 {
+	p := math.Pow(10, float64(decimals))
+	return math.Round(value*p) / p
+}
@@ -9,18 +9,29 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// Token purposes. Access tokens have no purpose; other tokens are only
+// accepted by the endpoint they were issued for.
+const (
+	TokenPurposeTwoFactor = "2fa"
+)
+
 type Claims struct {
-	UserID uint   `json:"user_id"`
-	Email  string `json:"email"`
+	UserID    uint   `json:"user_id"`
+	Email     string `json:"email"`
+	Role      string `json:"role"`
+	SessionID uint   `json:"sid"`
+	Purpose   string `json:"purpose,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(userID uint, email string) (string, error) {
+func GenerateToken(userID uint, email, role string, sessionID uint) (string, error) {
 	expirationTime := time.Now().Add(time.Duration(config.AppConfig.JWTExpiryHours) * time.Hour)
 
 	claims := &Claims{
-		UserID: userID,
-		Email:  email,
+		UserID:    userID,
+		Email:     email,
+		Role:      role,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -28,8 +39,43 @@ func GenerateToken(userID uint, email string) (string, error) {
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(config.AppConfig.JWTSecret))
+	return signClaims(claims)
+}
+
+// GenerateTwoFactorToken issues the short-lived token that links the
+// password step of login to the TOTP step
+func GenerateTwoFactorToken(userID uint, email string) (string, error) {
+	expirationTime := time.Now().Add(time.Duration(config.AppConfig.TwoFactorTokenExpiryMinutes) * time.Minute)
+
+	claims := &Claims{
+		UserID:  userID,
+		Email:   email,
+		Purpose: TokenPurposeTwoFactor,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expirationTime),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			Issuer:    "health-tracker",
+		},
+	}
+
+	return signClaims(claims)
+}
+
+func signClaims(claims *Claims) (string, error) {
+	if keyStore == nil {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		return token.SignedString([]byte(config.AppConfig.JWTSecret))
+	}
+
+	key, err := keyStore.SigningKeyAt(time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(signingMethod(key.Alg), claims)
+	token.Header["kid"] = key.KID
+
+	tokenString, err := token.SignedString(key.Private)
 
 	if err != nil {
 		return "", err
@@ -38,15 +84,20 @@ func GenerateToken(userID uint, email string) (string, error) {
 	return tokenString, nil
 }
 
+// ValidateToken validates an access token
 func ValidateToken(tokenString string) (*Claims, error) {
+	return validateToken(tokenString, "")
+}
+
+// ValidateTwoFactorToken validates a token from GenerateTwoFactorToken
+func ValidateTwoFactorToken(tokenString string) (*Claims, error) {
+	return validateToken(tokenString, TokenPurposeTwoFactor)
+}
+
+func validateToken(tokenString, purpose string) (*Claims, error) {
 	claims := &Claims{}
 
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("unexpected signing method")
-		}
-		return []byte(config.AppConfig.JWTSecret), nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, claims, verificationKey)
 
 	if err != nil {
 		return nil, err
@@ -56,5 +107,30 @@ func ValidateToken(tokenString string) (*Claims, error) {
 		return nil, errors.New("invalid token")
 	}
 
+	if claims.Purpose != purpose {
+		return nil, errors.New("token not valid for this use")
+	}
+
 	return claims, nil
 }
+
+// verificationKey picks the key for a token by its kid header. The
+// algorithm must match the key, so a public key is never used as an HMAC secret.
+func verificationKey(token *jwt.Token) (interface{}, error) {
+	if keyStore == nil {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("unexpected signing method")
+		}
+		return []byte(config.AppConfig.JWTSecret), nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := keyStore.lookup(kid, time.Now())
+	if !ok {
+		return nil, errors.New("unknown signing key")
+	}
+	if token.Method.Alg() != key.Alg {
+		return nil, errors.New("unexpected signing method")
+	}
+	return key.Public(), nil
+}
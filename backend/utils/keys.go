@@ -0,0 +1,351 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Supported JWT signing algorithms
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+// activateAtHeader is the PEM header that schedules when a key starts signing
+const activateAtHeader = "Activate-At"
+
+// keyPrepublishWindow is how long before activation a new key is written,
+// so every instance and JWKS consumer knows its public half in advance
+const keyPrepublishWindow = 24 * time.Hour
+
+// SigningKey is one asymmetric key in the key directory
+type SigningKey struct {
+	KID        string
+	Alg        string
+	Private    crypto.Signer
+	ActivateAt time.Time
+	RetireAt   time.Time // zero while the key is the newest one
+}
+
+// Public returns the verification key in the form jwt expects
+func (k *SigningKey) Public() interface{} {
+	return k.Private.Public()
+}
+
+// KeyStore holds the signing keys loaded from a directory. The newest key
+// whose activation time has passed signs new tokens. Superseded keys keep
+// verifying until every token they signed has expired.
+type KeyStore struct {
+	mu             sync.RWMutex
+	dir            string
+	alg            string
+	rotationPeriod time.Duration
+	retention      time.Duration
+	keys           []*SigningKey // sorted by ActivateAt ascending
+}
+
+var keyStore *KeyStore
+
+// InitKeyStore loads the key directory and starts the rotation schedule.
+// With HS256 no key store is used and tokens are signed with JWT_SECRET.
+func InitKeyStore(dir, alg string, rotationPeriod, retention time.Duration) error {
+	if alg == AlgHS256 {
+		keyStore = nil
+		return nil
+	}
+	if alg != AlgRS256 && alg != AlgEdDSA {
+		return fmt.Errorf("unsupported JWT algorithm %q", alg)
+	}
+
+	ks := &KeyStore{
+		dir:            dir,
+		alg:            alg,
+		rotationPeriod: rotationPeriod,
+		retention:      retention,
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	if err := ks.Rotate(time.Now()); err != nil {
+		return err
+	}
+
+	keyStore = ks
+	go ks.rotateLoop()
+
+	return nil
+}
+
+func (ks *KeyStore) rotateLoop() {
+	for {
+		time.Sleep(time.Hour)
+		if err := ks.Rotate(time.Now()); err != nil {
+			log.Printf("JWT key rotation failed: %v", err)
+		}
+	}
+}
+
+// Rotate reloads the directory and writes the next key when the current
+// one is due for replacement
+func (ks *KeyStore) Rotate(now time.Time) error {
+	if err := ks.reload(); err != nil {
+		return err
+	}
+
+	ks.mu.RLock()
+	var newest *SigningKey
+	if len(ks.keys) > 0 {
+		newest = ks.keys[len(ks.keys)-1]
+	}
+	ks.mu.RUnlock()
+
+	var activateAt time.Time
+	switch {
+	case newest == nil:
+		activateAt = now
+	case ks.rotationPeriod > 0 && !now.Before(newest.ActivateAt.Add(ks.rotationPeriod-keyPrepublishWindow)):
+		activateAt = newest.ActivateAt.Add(ks.rotationPeriod)
+		if activateAt.Before(now) {
+			activateAt = now
+		}
+	default:
+		return nil
+	}
+
+	key, err := ks.generate(activateAt)
+	if err != nil {
+		return err
+	}
+	log.Printf("🔑 Generated JWT signing key %s (%s), active from %s", key.KID, key.Alg, activateAt.Format(time.RFC3339))
+
+	return ks.reload()
+}
+
+func (ks *KeyStore) generate(activateAt time.Time) (*SigningKey, error) {
+	var signer crypto.Signer
+	var err error
+
+	switch ks.alg {
+	case AlgRS256:
+		signer, err = rsa.GenerateKey(rand.Reader, 2048)
+	case AlgEdDSA:
+		_, signer, err = ed25519.GenerateKey(rand.Reader)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(signer)
+	if err != nil {
+		return nil, err
+	}
+
+	kid := fmt.Sprintf("%s-%s", activateAt.UTC().Format("20060102T150405Z"), strings.ToLower(ks.alg))
+	block := &pem.Block{
+		Type:    "PRIVATE KEY",
+		Headers: map[string]string{activateAtHeader: activateAt.UTC().Format(time.RFC3339)},
+		Bytes:   der,
+	}
+
+	path := filepath.Join(ks.dir, kid+".pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(block), 0o600); err != nil {
+		return nil, err
+	}
+
+	return &SigningKey{KID: kid, Alg: ks.alg, Private: signer, ActivateAt: activateAt}, nil
+}
+
+// reload reads every *.pem file in the directory. The file name without
+// extension is the key ID.
+func (ks *KeyStore) reload() error {
+	paths, err := filepath.Glob(filepath.Join(ks.dir, "*.pem"))
+	if err != nil {
+		return err
+	}
+
+	var keys []*SigningKey
+	for _, path := range paths {
+		key, err := loadKeyFile(path)
+		if err != nil {
+			log.Printf("Skipping JWT key %s: %v", path, err)
+			continue
+		}
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ActivateAt.Before(keys[j].ActivateAt) })
+
+	// A key retires once its successor activates, and stays valid for
+	// verification for the retention period after that
+	for i := 0; i < len(keys)-1; i++ {
+		keys[i].RetireAt = keys[i+1].ActivateAt
+	}
+
+	ks.mu.Lock()
+	ks.keys = keys
+	ks.mu.Unlock()
+
+	return nil
+}
+
+func loadKeyFile(path string) (*SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	var parsed interface{}
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM type %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key := &SigningKey{KID: strings.TrimSuffix(filepath.Base(path), ".pem")}
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.Alg, key.Private = AlgRS256, k
+	case ed25519.PrivateKey:
+		key.Alg, key.Private = AlgEdDSA, k
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", parsed)
+	}
+
+	// Keys created outside the app (e.g. with openssl) activate at their mtime
+	if v, ok := block.Headers[activateAtHeader]; ok {
+		if key.ActivateAt, err = time.Parse(time.RFC3339, v); err != nil {
+			return nil, fmt.Errorf("invalid %s header: %w", activateAtHeader, err)
+		}
+	} else {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		key.ActivateAt = info.ModTime()
+	}
+
+	return key, nil
+}
+
+// SigningKeyAt returns the key that signs tokens at the given time
+func (ks *KeyStore) SigningKeyAt(now time.Time) (*SigningKey, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	for i := len(ks.keys) - 1; i >= 0; i-- {
+		if !ks.keys[i].ActivateAt.After(now) {
+			return ks.keys[i], nil
+		}
+	}
+	return nil, errors.New("no active signing key")
+}
+
+// VerificationKeys returns all keys whose tokens may still be valid,
+// including pre-published keys that are not signing yet
+func (ks *KeyStore) VerificationKeys(now time.Time) []*SigningKey {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	var keys []*SigningKey
+	for _, k := range ks.keys {
+		if k.RetireAt.IsZero() || now.Before(k.RetireAt.Add(ks.retention)) {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// lookup finds a verification key by ID
+func (ks *KeyStore) lookup(kid string, now time.Time) (*SigningKey, bool) {
+	for _, k := range ks.VerificationKeys(now) {
+		if k.KID == kid {
+			return k, true
+		}
+	}
+	return nil, false
+}
+
+// JWK is a public key in JSON Web Key format (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKSet is the document served at /.well-known/jwks.json
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// GetJWKS returns the public keys that other services need to verify tokens
+func GetJWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	if keyStore == nil {
+		return set
+	}
+
+	for _, k := range keyStore.VerificationKeys(time.Now()) {
+		jwk := JWK{Kid: k.KID, Use: "sig", Alg: k.Alg}
+		switch pub := k.Public().(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		default:
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	return set
+}
+
+func signingMethod(alg string) jwt.SigningMethod {
+	switch alg {
+	case AlgRS256:
+		return jwt.SigningMethodRS256
+	case AlgEdDSA:
+		return jwt.SigningMethodEdDSA
+	default:
+		return jwt.SigningMethodHS256
+	}
+}
@@ -1,18 +1,11 @@
 import { useState } from 'react';
 import { Link } from 'react-router-dom';
-import { Heart, Mail, Lock, Loader2, ArrowLeft, CheckCircle, Eye, EyeOff } from 'lucide-react';
-import axios from 'axios';
+import { Heart, Mail, Loader2, ArrowLeft, CheckCircle } from 'lucide-react';
+import { authAPI } from '../services/api';
 import './Auth.css';
 
-// Menggunakan Link Koyeb Kamu
-const API_URL = 'https://bright-swan-1tubes-sismul-cc0e96ef.koyeb.app/api';
-
 const ForgotPassword = () => {
     const [email, setEmail] = useState('');
-    const [newPassword, setNewPassword] = useState('');
-    const [confirmPassword, setConfirmPassword] = useState('');
-    const [showPassword, setShowPassword] = useState(false);
-    const [showConfirmPassword, setShowConfirmPassword] = useState(false);
     const [loading, setLoading] = useState(false);
     const [success, setSuccess] = useState(false);
     const [error, setError] = useState('');
@@ -20,29 +13,14 @@ const ForgotPassword = () => {
     const handleSubmit = async (e) => {
         e.preventDefault();
         setError('');
-
-        // Validate passwords match
-        if (newPassword !== confirmPassword) {
-            setError('Password baru dan konfirmasi password tidak sama');
-            return;
-        }
-
-        // Validate password length
-        if (newPassword.length < 6) {
-            setError('Password minimal 6 karakter');
-            return;
-        }
-
         setLoading(true);
 
         try {
-            await axios.post(`${API_URL}/auth/reset-password`, {
-                email: email,
-                new_password: newPassword
-            });
+            // Respons selalu sukses agar email terdaftar tidak bisa ditebak
+            await authAPI.forgotPassword(email);
             setSuccess(true);
         } catch (err) {
-            setError(err.response?.data?.error || 'Gagal reset password. Pastikan email terdaftar.');
+            setError(err.response?.data?.error || 'Gagal mengirim link reset password. Coba lagi nanti.');
         } finally {
             setLoading(false);
         }
@@ -73,23 +51,23 @@ const ForgotPassword = () => {
                         <Heart className="logo-icon" />
                         <span>Live for Health</span>
                     </div>
-                    <h1>Reset Password</h1>
-                    <p>Masukkan email dan password baru Anda</p>
+                    <h1>Lupa Password</h1>
+                    <p>Kami akan mengirim link untuk membuat password baru</p>
                 </div>
 
                 {success ? (
                     <div className="auth-form">
                         <div className="success-message">
                             <CheckCircle size={32} style={{ marginBottom: 12 }} />
-                            <p>Password berhasil direset!</p>
+                            <p>Cek email Anda!</p>
                             <p style={{ fontSize: 13, marginTop: 8, opacity: 0.8 }}>
-                                Silakan login dengan password baru Anda.
+                                Jika {email} terdaftar, link reset password sudah dikirim ke email tersebut.
                             </p>
                         </div>
                         <div className="back-to-login" style={{ marginTop: 24 }}>
                             <Link to="/login">
                                 <ArrowLeft size={18} />
-                                Login Sekarang
+                                Kembali ke Login
                             </Link>
                         </div>
                     </div>
@@ -97,7 +75,7 @@ const ForgotPassword = () => {
                     <form onSubmit={handleSubmit} className="auth-form">
                         <div className="forgot-password-info">
                             <p>
-                                🔐 Masukkan email yang terdaftar dan password baru Anda.
+                                🔐 Masukkan email yang terdaftar. Link reset password akan dikirim ke email tersebut.
                             </p>
                         </div>
 
@@ -114,48 +92,8 @@ const ForgotPassword = () => {
                             />
                         </div>
 
-                        <div className="input-group">
-                            <Lock className="input-icon" />
-                            <input
-                                type={showPassword ? "text" : "password"}
-                                placeholder="Password baru"
-                                value={newPassword}
-                                onChange={(e) => setNewPassword(e.target.value)}
-                                required
-                                minLength={6}
-                            />
-                            <button
-                                type="button"
-                                className="password-toggle"
-                                onClick={() => setShowPassword(!showPassword)}
-                                tabIndex={-1}
-                            >
-                                {showPassword ? <EyeOff size={20} /> : <Eye size={20} />}
-                            </button>
-                        </div>
-
-                        <div className="input-group">
-                            <Lock className="input-icon" />
-                            <input
-                                type={showConfirmPassword ? "text" : "password"}
-                                placeholder="Konfirmasi password baru"
-                                value={confirmPassword}
-                                onChange={(e) => setConfirmPassword(e.target.value)}
-                                required
-                                minLength={6}
-                            />
-                            <button
-                                type="button"
-                                className="password-toggle"
-                                onClick={() => setShowConfirmPassword(!showConfirmPassword)}
-                                tabIndex={-1}
-                            >
-                                {showConfirmPassword ? <EyeOff size={20} /> : <Eye size={20} />}
-                            </button>
-                        </div>
-
                         <button type="submit" className="auth-button" disabled={loading}>
-                            {loading ? <Loader2 className="spinner" /> : 'Reset Password'}
+                            {loading ? <Loader2 className="spinner" /> : 'Kirim Link Reset'}
                         </button>
 
                         <div className="back-to-login">
@@ -0,0 +1,94 @@
+import { useEffect, useRef, useState } from 'react';
+import { Link, useSearchParams } from 'react-router-dom';
+import { Heart, Loader2, ArrowLeft, CheckCircle } from 'lucide-react';
+import { authAPI } from '../services/api';
+import { useAuth } from '../context/AuthContext';
+import './Auth.css';
+
+const VerifyEmail = () => {
+    const [searchParams] = useSearchParams();
+    const token = searchParams.get('token') || '';
+    const { user, updateUser } = useAuth();
+    const [status, setStatus] = useState(token ? 'loading' : 'error');
+    const [error, setError] = useState(token ? '' : 'Link verifikasi tidak valid.');
+    // Token hanya bisa dipakai sekali, jangan kirim ulang saat effect jalan dua kali
+    const requested = useRef(false);
+
+    useEffect(() => {
+        if (!token || requested.current) return;
+        requested.current = true;
+
+        authAPI.verifyEmail(token)
+            .then(() => setStatus('success'))
+            .catch((err) => {
+                setError(err.response?.data?.error || 'Gagal memverifikasi email. Link mungkin sudah kedaluwarsa.');
+                setStatus('error');
+            });
+    }, [token]);
+
+    useEffect(() => {
+        if (status === 'success' && user && !user.email_verified) {
+            updateUser({ ...user, email_verified: true });
+        }
+    }, [status, user, updateUser]);
+
+    return (
+        <div className="auth-container">
+            {/* Animated Background Elements */}
+            <div className="auth-bg-elements">
+                <div className="particle"></div>
+                <div className="particle"></div>
+                <div className="particle"></div>
+                <div className="particle"></div>
+                <div className="particle"></div>
+                <div className="glow-orb"></div>
+                <div className="glow-orb"></div>
+                <div className="glow-orb"></div>
+                <div className="star"></div>
+                <div className="star"></div>
+                <div className="star"></div>
+                <div className="star"></div>
+                <div className="star"></div>
+            </div>
+
+            <div className="auth-card">
+                <div className="auth-header">
+                    <div className="auth-logo">
+                        <Heart className="logo-icon" />
+                        <span>Live for Health</span>
+                    </div>
+                    <h1>Verifikasi Email</h1>
+                </div>
+
+                <div className="auth-form">
+                    {status === 'loading' && (
+                        <div style={{ textAlign: 'center' }}>
+                            <Loader2 className="spinner" />
+                            <p>Memverifikasi email Anda...</p>
+                        </div>
+                    )}
+
+                    {status === 'success' && (
+                        <div className="success-message">
+                            <CheckCircle size={32} style={{ marginBottom: 12 }} />
+                            <p>Email berhasil diverifikasi!</p>
+                        </div>
+                    )}
+
+                    {status === 'error' && <div className="auth-error">{error}</div>}
+
+                    {status !== 'loading' && (
+                        <div className="back-to-login" style={{ marginTop: 24 }}>
+                            <Link to={user ? '/dashboard' : '/login'}>
+                                <ArrowLeft size={18} />
+                                {user ? 'Ke Dashboard' : 'Ke Halaman Login'}
+                            </Link>
+                        </div>
+                    )}
+                </div>
+            </div>
+        </div>
+    );
+};
+
+export default VerifyEmail;
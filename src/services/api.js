@@ -38,6 +38,9 @@ export const authAPI = {
     login: (data) => api.post('/auth/login', data),
     getProfile: () => api.get('/auth/me'),
     updateProfile: (data) => api.put('/auth/profile', data),
+    forgotPassword: (email) => api.post('/auth/forgot-password', { email }),
+    resetPassword: (token, newPassword) => api.post('/auth/reset-password', { token, new_password: newPassword }),
+    verifyEmail: (token) => api.get('/auth/verify', { params: { token } }),
 };
 
 // Health API